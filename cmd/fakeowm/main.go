@@ -0,0 +1,23 @@
+// Command fakeowm serves a fake OpenWeatherMap API with canned responses,
+// for offline demos of weatherornot. Point weatherornot at it with:
+//
+//	weatherornot config set endpoints.openweathermap http://localhost:8080
+//	weatherornot config set endpoints.openweathermap_geo http://localhost:8080/geo/1.0
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/james-see/weatherornot/internal/apitest"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "Address to listen on")
+	apiKey := flag.String("key", "", "Only accept this API key (default: accept any)")
+	flag.Parse()
+
+	log.Printf("Fake OpenWeatherMap API listening on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, apitest.NewHandler(*apiKey)))
+}
@@ -0,0 +1,257 @@
+// Command gengazetteer builds the embedded gazetteer table from the
+// GeoNames cities and admin1 code dumps. It is run by go generate in
+// internal/gazetteer:
+//
+//	go generate ./internal/gazetteer
+//
+// Sources may be URLs or local files, and the cities dump may be zipped.
+// GeoNames rebuilds its dumps daily, so the table is generated by hand
+// and committed rather than at build time. Each source's SHA-256 is
+// written to the table header, and -cities-sha256 and -admin1-sha256
+// refuse sources that differ from a snapshot that was reviewed before.
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	geoNamesCitiesURL = "https://download.geonames.org/export/dump/cities15000.zip"
+	geoNamesAdmin1URL = "https://download.geonames.org/export/dump/admin1CodesASCII.txt"
+
+	// maxAlternates is how many alternate names are kept per city
+	maxAlternates = 4
+)
+
+// city is one row of the gazetteer table
+type city struct {
+	name, ascii, alternates string
+	lat, lon                string
+	country                 string
+	admin1Code, admin1      string
+	population              int
+	timezone                string
+}
+
+func main() {
+	citiesSrc := flag.String("cities", geoNamesCitiesURL, "GeoNames cities dump, URL or file")
+	admin1Src := flag.String("admin1", geoNamesAdmin1URL, "GeoNames admin1 codes, URL or file")
+	citiesSum := flag.String("cities-sha256", "", "Expected SHA-256 of the cities dump")
+	admin1Sum := flag.String("admin1-sha256", "", "Expected SHA-256 of the admin1 codes")
+	minPopulation := flag.Int("min-population", 15000, "Leave out smaller cities")
+	out := flag.String("out", "cities.tsv", "Output file")
+	flag.Parse()
+
+	cities := source{src: *citiesSrc, sum: *citiesSum}
+	admin1 := source{src: *admin1Src, sum: *admin1Sum}
+	if err := run(cities, admin1, *minPopulation, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "gengazetteer:", err)
+		os.Exit(1)
+	}
+}
+
+// source is an input dump and the SHA-256 it must have, if any
+type source struct {
+	src string
+	sum string
+}
+
+func run(citiesSrc, admin1Src source, minPopulation int, out string) error {
+	admin1Raw, admin1Sum, err := fetchVerified(admin1Src)
+	if err != nil {
+		return err
+	}
+	admin1 := parseAdmin1(admin1Raw)
+
+	citiesRaw, citiesSum, err := fetchVerified(citiesSrc)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(citiesSrc.src, ".zip") {
+		if citiesRaw, err = unzip(citiesRaw); err != nil {
+			return err
+		}
+	}
+
+	cities, err := parseCities(citiesRaw, admin1, minPopulation)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# weatherornot gazetteer: name, ASCII name, alternate names, latitude, longitude,\n")
+	buf.WriteString("# country code, admin1 code, admin1 name, population, timezone\n")
+	buf.WriteString("# Data from GeoNames (https://www.geonames.org), licensed under CC BY 4.0.\n")
+	fmt.Fprintf(&buf, "# Source: %s (SHA-256 %s)\n", citiesSrc.src, citiesSum)
+	fmt.Fprintf(&buf, "# Source: %s (SHA-256 %s)\n", admin1Src.src, admin1Sum)
+	buf.WriteString("# Generated by cmd/gengazetteer; do not edit.\n")
+	for _, c := range cities {
+		fmt.Fprintf(&buf, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			c.name, c.ascii, c.alternates, c.lat, c.lon, c.country, c.admin1Code, c.admin1, c.population, c.timezone)
+	}
+
+	if err := os.WriteFile(out, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d cities to %s\n", len(cities), out)
+	return nil
+}
+
+// fetchVerified reads a source and returns it with its SHA-256, failing
+// when the source has an expected sum that does not match
+func fetchVerified(s source) ([]byte, string, error) {
+	raw, err := fetch(s.src)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(raw)
+	got := hex.EncodeToString(sum[:])
+	if s.sum != "" && !strings.EqualFold(s.sum, got) {
+		return nil, "", fmt.Errorf("%s has SHA-256 %s, expected %s", s.src, got, s.sum)
+	}
+	fmt.Printf("%s: SHA-256 %s\n", s.src, got)
+	return raw, got, nil
+}
+
+// fetch reads src from a URL or a local file
+func fetch(src string) ([]byte, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		return os.ReadFile(src)
+	}
+
+	resp, err := http.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", src, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// unzip returns the first .txt file in a zip archive
+func unzip(raw []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".txt") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("no .txt file in archive")
+}
+
+// parseAdmin1 maps "CC.code" keys to admin1 names
+func parseAdmin1(raw []byte) map[string]string {
+	names := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		f := strings.Split(scanner.Text(), "\t")
+		if len(f) >= 2 {
+			names[f[0]] = f[1]
+		}
+	}
+	return names
+}
+
+// parseCities reads the GeoNames cities dump, largest cities first within
+// each country
+func parseCities(raw []byte, admin1 map[string]string, minPopulation int) ([]city, error) {
+	var cities []city
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		f := strings.Split(scanner.Text(), "\t")
+		if len(f) < 18 {
+			continue
+		}
+
+		population, _ := strconv.Atoi(f[14])
+		if population < minPopulation {
+			continue
+		}
+
+		cities = append(cities, city{
+			name:       clean(f[1]),
+			ascii:      clean(f[2]),
+			alternates: alternates(f[3], f[1], f[2]),
+			lat:        f[4],
+			lon:        f[5],
+			country:    f[8],
+			admin1Code: f[10],
+			admin1:     clean(admin1[f[8]+"."+f[10]]),
+			population: population,
+			timezone:   f[17],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(cities, func(i, j int) bool {
+		if cities[i].country != cities[j].country {
+			return cities[i].country < cities[j].country
+		}
+		return cities[i].population > cities[j].population
+	})
+	return cities, nil
+}
+
+// alternates picks a few plain ASCII alternate names such as "NYC" or
+// "Munich", leaving out translations and codes
+func alternates(list, name, ascii string) string {
+	var keep []string
+	seen := map[string]bool{strings.ToLower(name): true, strings.ToLower(ascii): true}
+	for _, alt := range strings.Split(list, ",") {
+		alt = clean(alt)
+		if len(keep) == maxAlternates {
+			break
+		}
+		if len(alt) < 2 || len(alt) > 40 || seen[strings.ToLower(alt)] || !plainName(alt) {
+			continue
+		}
+		seen[strings.ToLower(alt)] = true
+		keep = append(keep, alt)
+	}
+	return strings.Join(keep, ",")
+}
+
+// plainName reports whether s is made of ASCII letters, spaces and the
+// punctuation found in place names
+func plainName(s string) bool {
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r == ' ', r == '-', r == '.', r == '\'':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// clean removes characters that would break the table format
+func clean(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("\t", " ", "\n", " ", ",", " ").Replace(s))
+}
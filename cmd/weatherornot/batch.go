@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/config"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+var (
+	batchFile    string
+	batchWorkers int
+	batchRate    float64
+	batchOutput  string
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [location...]",
+	Short: "Show current conditions for many locations at once",
+	Long: `Fetch current conditions for many locations concurrently and print them
+as a table, JSON or CSV.
+
+Locations are read from the arguments, from a file given with --file, or from
+stdin, one per line. Favorites are written as @name. Blank lines and lines
+starting with # are skipped.`,
+	Example: `  weatherornot batch 10001 "Denver,CO" @home
+  weatherornot batch --file sites.txt --output csv
+  cat sites.txt | weatherornot batch --workers 8 --output json`,
+	RunE: runBatch,
+}
+
+func init() {
+	batchCmd.Flags().StringVarP(&batchFile, "file", "i", "", "Read locations from a file, - for stdin")
+	batchCmd.Flags().IntVarP(&batchWorkers, "workers", "w", 4, "Number of locations fetched at the same time")
+	batchCmd.Flags().Float64Var(&batchRate, "rate", 5, "Maximum locations started per second, 0 for no limit")
+	batchCmd.Flags().StringVarP(&batchOutput, "output", "o", "table", "Output format: table, json or csv")
+
+	rootCmd.AddCommand(batchCmd)
+}
+
+// batchRecord is one line of batch output. Conditions are left out for
+// locations that failed.
+type batchRecord struct {
+	Query string `json:"query"`
+	*batchConditions
+	Error string `json:"error,omitempty"`
+}
+
+// batchConditions are the current conditions at a batch location
+type batchConditions struct {
+	Location    string  `json:"location"`
+	Country     string  `json:"country"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Temperature float64 `json:"temperature"`
+	FeelsLike   float64 `json:"feels_like"`
+	Humidity    int     `json:"humidity"`
+	WindSpeed   float64 `json:"wind_speed"`
+	Condition   string  `json:"condition"`
+	Units       string  `json:"units"`
+	Stale       bool    `json:"stale"`
+}
+
+func runBatch(cmd *cobra.Command, args []string) error {
+	if batchOutput != "table" && batchOutput != "json" && batchOutput != "csv" {
+		return fmt.Errorf("output must be table, json or csv")
+	}
+	if batchWorkers < 1 {
+		return fmt.Errorf("workers must be at least 1")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	applyFlags(cfg)
+
+	queries, err := batchQueries(args)
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no locations given; pass them as arguments, with --file or on stdin")
+	}
+
+	provider, done, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	records := fetchBatch(cmd.Context(), cfg, provider, queries)
+
+	switch batchOutput {
+	case "json":
+		err = writeBatchJSON(os.Stdout, records)
+	case "csv":
+		err = writeBatchCSV(os.Stdout, records)
+	default:
+		err = writeBatchTable(os.Stdout, records, cfg.Units)
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range records {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d locations failed", failed, len(records))
+	}
+	return nil
+}
+
+// batchQueries collects locations from the arguments, --file and stdin
+func batchQueries(args []string) ([]string, error) {
+	queries := append([]string(nil), args...)
+
+	switch {
+	case batchFile == "-":
+		lines, err := readLocations(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, lines...)
+	case batchFile != "":
+		f, err := os.Open(batchFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not open locations file: %w", err)
+		}
+		defer f.Close()
+
+		lines, err := readLocations(f)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, lines...)
+	case len(args) == 0 && !isTerminal(os.Stdin):
+		return readLocations(os.Stdin)
+	}
+
+	return queries, nil
+}
+
+// readLocations reads one location per line, skipping blank lines and
+// # comments
+func readLocations(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading locations: %w", err)
+	}
+	return lines, nil
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// fetchBatch fetches every query with a bounded pool of workers sharing
+// one rate limiter. Records keep the order of the queries.
+func fetchBatch(ctx context.Context, cfg *config.Config, provider api.Provider, queries []string) []batchRecord {
+	records := make([]batchRecord, len(queries))
+	limiter := newRateLimiter(batchRate)
+	defer limiter.stop()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < batchWorkers && w < len(queries); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				records[i] = fetchBatchRecord(ctx, cfg, provider, limiter, queries[i])
+			}
+		}()
+	}
+
+	for i := range queries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return records
+}
+
+// fetchBatchRecord fetches the current conditions for one query
+func fetchBatchRecord(ctx context.Context, cfg *config.Config, provider api.Provider, limiter *rateLimiter, query string) batchRecord {
+	record := batchRecord{Query: query}
+
+	loc, err := parseLocationArg(ctx, cfg, query)
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+
+	if err := limiter.wait(ctx); err != nil {
+		record.Error = err.Error()
+		return record
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	data, err := fetchWeather(ctx, provider, loc)
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+
+	record.batchConditions = &batchConditions{
+		Location:    data.Location.Name,
+		Country:     data.Location.Country,
+		Latitude:    data.Location.Latitude,
+		Longitude:   data.Location.Longitude,
+		Temperature: data.Current.Temperature,
+		FeelsLike:   data.Current.FeelsLike,
+		Humidity:    data.Current.Humidity,
+		WindSpeed:   data.Current.WindSpeed,
+		Condition:   data.Current.Condition,
+		Units:       cfg.Units,
+		Stale:       data.Stale,
+	}
+	return record
+}
+
+// parseLocationArg parses a location argument, resolving @name to the
+// favorite of that name and "auto" to the current position
+func parseLocationArg(ctx context.Context, cfg *config.Config, arg string) (*location.ParsedLocation, error) {
+	if name, ok := strings.CutPrefix(arg, "@"); ok {
+		fav, exists := cfg.Favorites[name]
+		if !exists {
+			return nil, fmt.Errorf("favorite '%s' not found", name)
+		}
+		arg = fav
+	}
+	if isAutoLocation(arg) {
+		return hereLocation(ctx, cfg)
+	}
+
+	loc, err := locationParser(cfg).Parse(arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse location: %w", err)
+	}
+	return loc, nil
+}
+
+// rateLimiter spaces out work shared by several goroutines. A nil
+// rateLimiter does not limit.
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+// newRateLimiter allows perSecond events per second, or any number if
+// perSecond is not positive
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{ticker: time.NewTicker(time.Duration(float64(time.Second) / perSecond))}
+}
+
+// wait blocks until the next event is allowed or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case <-l.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop releases the limiter
+func (l *rateLimiter) stop() {
+	if l != nil {
+		l.ticker.Stop()
+	}
+}
+
+// writeBatchTable writes records as an aligned table
+func writeBatchTable(w io.Writer, records []batchRecord, units string) error {
+	tempUnit, windUnit := "°F", "mph"
+	switch units {
+	case "metric":
+		tempUnit, windUnit = "°C", "m/s"
+	case "standard":
+		tempUnit, windUnit = "K", "m/s"
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOCATION\tTEMP\tFEELS LIKE\tHUMIDITY\tWIND\tCONDITION")
+	for _, r := range records {
+		if r.batchConditions == nil {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\terror: %s\n", r.Query, r.Error)
+			continue
+		}
+
+		name := r.Location
+		if r.Country != "" {
+			name += ", " + r.Country
+		}
+		condition := r.Condition
+		if r.Stale {
+			condition += " (cached)"
+		}
+		fmt.Fprintf(tw, "%s\t%.1f%s\t%.1f%s\t%d%%\t%.1f %s\t%s\n",
+			name, r.Temperature, tempUnit, r.FeelsLike, tempUnit, r.Humidity, r.WindSpeed, windUnit, condition)
+	}
+	return tw.Flush()
+}
+
+// writeBatchJSON writes records as a JSON array
+func writeBatchJSON(w io.Writer, records []batchRecord) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// writeBatchCSV writes records as CSV with a header row
+func writeBatchCSV(w io.Writer, records []batchRecord) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"query", "location", "country", "latitude", "longitude", "temperature",
+		"feels_like", "humidity", "wind_speed", "condition", "units", "stale", "error"})
+
+	for _, r := range records {
+		if r.batchConditions == nil {
+			cw.Write([]string{r.Query, "", "", "", "", "", "", "", "", "", "", "", r.Error})
+			continue
+		}
+		cw.Write([]string{
+			r.Query,
+			r.Location,
+			r.Country,
+			strconv.FormatFloat(r.Latitude, 'f', 4, 64),
+			strconv.FormatFloat(r.Longitude, 'f', 4, 64),
+			strconv.FormatFloat(r.Temperature, 'f', 1, 64),
+			strconv.FormatFloat(r.FeelsLike, 'f', 1, 64),
+			strconv.Itoa(r.Humidity),
+			strconv.FormatFloat(r.WindSpeed, 'f', 1, 64),
+			r.Condition,
+			r.Units,
+			strconv.FormatBool(r.Stale),
+			"",
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
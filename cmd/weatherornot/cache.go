@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/james-see/weatherornot/internal/cache"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage cached weather responses",
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePathCmd)
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached responses",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := cache.Dir()
+		if err != nil {
+			return err
+		}
+		if err := cache.New(dir).Clear(); err != nil {
+			return err
+		}
+		fmt.Printf("Cleared cache at: %s\n", dir)
+		return nil
+	},
+}
+
+var cachePathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show cache directory path",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := cache.Dir()
+		if err != nil {
+			return err
+		}
+		fmt.Println(dir)
+		return nil
+	},
+}
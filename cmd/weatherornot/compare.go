@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/config"
+	"github.com/james-see/weatherornot/internal/display"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <location> <location>...",
+	Short: "Compare the weather at several locations side by side",
+	Long: `Show the current conditions and the next hours of several locations in
+columns next to each other, with their hourly temperatures on one chart.
+
+Locations can be favorite names, @name, or anything accepted as a location.`,
+	Example: `  weatherornot compare home work "Denver,CO"
+  weatherornot compare 10001 90210 --hours 8 --graph=false`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runCompare,
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	applyFlags(cfg)
+
+	locations := make([]*location.ParsedLocation, len(args))
+	for i, arg := range args {
+		loc, err := compareLocation(cmd.Context(), cfg, arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		locations[i] = loc
+	}
+
+	provider, done, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	// Fetch all locations at once; the comparison needs every one of them
+	results := make([]*api.WeatherData, len(locations))
+	errs := make([]error, len(locations))
+	var wg sync.WaitGroup
+	for i, loc := range locations {
+		wg.Add(1)
+		go func(i int, loc *location.ParsedLocation) {
+			defer wg.Done()
+			results[i], errs[i] = fetchWeather(ctx, provider, loc)
+		}(i, loc)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return withHint(fmt.Errorf("failed to fetch weather for %s: %w", args[i], err))
+		}
+	}
+
+	renderer := display.NewWidgetDisplay(cfg.ShowColors, cfg.Units)
+	fmt.Println(renderer.RenderComparison(results, args, hours))
+
+	if showGraph {
+		chartRenderer := display.NewChartDisplay(cfg.ShowColors, cfg.Units)
+		fmt.Print(chartRenderer.RenderComparisonChart(results, args, hours))
+	}
+
+	return nil
+}
+
+// compareLocation resolves a compare argument. Bare favorite names are
+// accepted in addition to everything parseLocationArg understands.
+func compareLocation(ctx context.Context, cfg *config.Config, arg string) (*location.ParsedLocation, error) {
+	if fav, exists := cfg.Favorites[arg]; exists {
+		arg = fav
+	}
+	return parseLocationArg(ctx, cfg, arg)
+}
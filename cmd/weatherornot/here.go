@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/cache"
+	"github.com/james-see/weatherornot/internal/config"
+	"github.com/james-see/weatherornot/internal/display"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+// autoLocation is the location that stands for wherever the machine is
+const autoLocation = "auto"
+
+var hereCmd = &cobra.Command{
+	Use:   "here",
+	Short: "Show the weather where you are",
+	Long: `Show the weather at the machine's current position, found with an IP
+geolocation service or a local gpsd daemon as set by here_source. The
+position is cached for here_ttl, so moving machines are located again
+regularly without asking on every run. Setting default_location to "auto"
+does the same when no location is given.`,
+	Example: `  weatherornot here
+  weatherornot here --coords
+  weatherornot config set here_source gpsd
+  weatherornot config set default_location auto`,
+	Args: cobra.NoArgs,
+	RunE: runHere,
+}
+
+// hereCoords prints the position rather than the weather there
+var hereCoords bool
+
+func init() {
+	hereCmd.Flags().BoolVar(&hereCoords, "coords", false, "Print the current position instead of the weather")
+	rootCmd.AddCommand(hereCmd)
+}
+
+func runHere(cmd *cobra.Command, args []string) error {
+	if !hereCoords {
+		return runWeather(cmd, []string{autoLocation})
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	pos, err := currentPosition(cmd.Context(), cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%.6f,%.6f\n", pos.Latitude, pos.Longitude)
+	return nil
+}
+
+// isAutoLocation reports whether a location asks for the current position
+func isAutoLocation(s string) bool {
+	return strings.EqualFold(strings.TrimSpace(s), autoLocation)
+}
+
+// hereLocation returns the current position as a parsed location
+func hereLocation(ctx context.Context, cfg *config.Config) (*location.ParsedLocation, error) {
+	pos, err := currentPosition(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &location.ParsedLocation{
+		Type:       location.TypeCoords,
+		Latitude:   pos.Latitude,
+		Longitude:  pos.Longitude,
+		Confidence: location.ConfidenceCertain,
+	}, nil
+}
+
+var (
+	hereMu  sync.Mutex
+	herePos *api.Position // the position found in this run
+)
+
+// currentPosition returns the machine's position, finding it once per run
+// so that several "auto" locations, as in batch, share one lookup
+func currentPosition(ctx context.Context, cfg *config.Config) (*api.Position, error) {
+	hereMu.Lock()
+	defer hereMu.Unlock()
+	if herePos == nil {
+		pos, err := findPosition(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		herePos = pos
+	}
+	return herePos, nil
+}
+
+// findPosition finds the machine's position with the configured source.
+// A position younger than here_ttl is reused from the cache; with --offline,
+// or when the source cannot be reached, the last known position is used
+// whatever its age.
+func findPosition(ctx context.Context, cfg *config.Config) (*api.Position, error) {
+	ttl, err := time.ParseDuration(cfg.HereTTL)
+	if err != nil {
+		return nil, fmt.Errorf("here_ttl must be a duration such as 30m or 2h: %w", err)
+	}
+
+	last, storedAt, haveLast := cachedPosition(cfg)
+	if haveLast && (offline || !refresh && time.Since(storedAt) < ttl) {
+		return last, nil
+	}
+	if offline {
+		return nil, fmt.Errorf("no cached position; run once without --offline to find it")
+	}
+
+	locator, err := api.NewLocator(hereSource(cfg), cfg.HereIPURL, cfg.HereGPSDAddress, cfg.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pos, err := locator.Locate(ctx)
+	if err != nil {
+		if haveLast {
+			fmt.Fprintf(os.Stderr, "Could not find the current position (%v); using the one found %s\n",
+				err, display.FormatAge(time.Since(storedAt)))
+			return last, nil
+		}
+		return nil, fmt.Errorf("failed to find current position: %w", err)
+	}
+
+	if dir, err := cache.Dir(); err == nil && !noCache {
+		// Failing to cache is not worth failing the command for
+		_ = cache.New(dir).Put(positionKey(cfg), pos)
+	}
+	return pos, nil
+}
+
+// cachedPosition returns the last position found with the configured
+// source, and when it was found. Nothing is read with --no-cache.
+func cachedPosition(cfg *config.Config) (*api.Position, time.Time, bool) {
+	dir, err := cache.Dir()
+	if err != nil || noCache {
+		return nil, time.Time{}, false
+	}
+	var pos api.Position
+	storedAt, ok := cache.New(dir).Load(positionKey(cfg), &pos)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return &pos, storedAt, true
+}
+
+// hereSource returns the configured position source
+func hereSource(cfg *config.Config) string {
+	if source := strings.ToLower(strings.TrimSpace(cfg.HereSource)); source != "" {
+		return source
+	}
+	return api.PositionIP
+}
+
+// positionKey is the cache key of the position found with the configured
+// source, kept apart so switching sources does not reuse the other's fix
+func positionKey(cfg *config.Config) string {
+	return "here-position:" + hereSource(cfg)
+}
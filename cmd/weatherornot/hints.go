@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+// withHint adds advice on how to fix a failed weather lookup to err
+func withHint(err error) error {
+	if hint := errorHint(err); hint != "" {
+		return fmt.Errorf("%w\nHint: %s", err, hint)
+	}
+	return err
+}
+
+// withSuggestions adds "did you mean" suggestions to an error for a place
+// that could not be found
+func withSuggestions(err error, parser location.Parser, input string) error {
+	if !errors.Is(err, api.ErrNotFound) {
+		return err
+	}
+	if suggestions := parser.Suggest(input); len(suggestions) > 0 {
+		return fmt.Errorf("%w; did you mean %s?", err, location.QuoteList(suggestions))
+	}
+	return err
+}
+
+// errorHint returns advice for a failed weather lookup, or "" if there is
+// nothing useful to say
+func errorHint(err error) string {
+	var apiErr *api.APIError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, api.ErrNoCachedData):
+		return "run once without --offline to cache this location"
+	case errors.Is(err, api.ErrUnauthorized):
+		return "check your API key with `weatherornot config set api_key <key>` (new OpenWeatherMap keys take up to two hours to activate), " +
+			"or use a provider without keys: `weatherornot config set provider openmeteo`"
+	case errors.Is(err, api.ErrNotFound):
+		return "check the spelling, or add a state or country code, e.g. \"Springfield,IL,US\""
+	case errors.Is(err, api.ErrRateLimited):
+		hint := "the API quota is used up"
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			hint += fmt.Sprintf("; try again in %s", apiErr.RetryAfter.Round(time.Second))
+		}
+		return hint + ". Raising cache_current_ttl makes weatherornot call the API less often"
+	case errors.Is(err, api.ErrServer):
+		return "the weather service is having trouble; try again later, or add a fallback with " +
+			"`weatherornot config set fallback_providers openmeteo`"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "the weather service did not answer within --timeout; try a longer one, e.g. --timeout 1m"
+	case errors.Is(err, context.Canceled):
+		return ""
+	case errors.As(err, &netErr):
+		return "check your network connection; `weatherornot --offline` shows the last known data"
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/james-see/weatherornot/internal/config"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+var locateCmd = &cobra.Command{
+	Use:   "locate <lat,lon>",
+	Short: "Show the place at coordinates",
+	Long: `Resolve coordinates to a "City, State, Country" label with the configured
+provider, without fetching any weather. Results are cached like other
+geocoding lookups. Geohashes, Plus Codes and Maidenhead locators are
+accepted as well, and --grids also prints the point in those formats.`,
+	Example: `  weatherornot locate "40.7128,-74.0060"
+  weatherornot locate 849VCWC8+R9
+  weatherornot locate --grids CM87wj`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLocate,
+}
+
+// locateGrids prints the geohash, Plus Code and Maidenhead locator too
+var locateGrids bool
+
+func init() {
+	locateCmd.Flags().BoolVar(&locateGrids, "grids", false, "Also show the geohash, Plus Code and Maidenhead locator")
+	rootCmd.AddCommand(locateCmd)
+}
+
+func runLocate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	applyFlags(cfg)
+
+	loc, err := location.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse location: %w", err)
+	}
+	if loc.Type != location.TypeCoords {
+		return fmt.Errorf("%q is not a coordinate pair, e.g. \"40.7128,-74.0060\"", args[0])
+	}
+
+	provider, done, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	place, err := provider.ReverseGeocode(ctx, loc.Latitude, loc.Longitude)
+	if err != nil {
+		return withHint(fmt.Errorf("failed to locate %.4f,%.4f: %w", loc.Latitude, loc.Longitude, err))
+	}
+
+	fmt.Println(placeLabel(*place))
+	if locateGrids {
+		fmt.Printf("Geohash:    %s\n", location.EncodeGeohash(loc.Latitude, loc.Longitude, 9))
+		fmt.Printf("Plus Code:  %s\n", location.EncodePlusCode(loc.Latitude, loc.Longitude, 10))
+		fmt.Printf("Maidenhead: %s\n", location.EncodeMaidenhead(loc.Latitude, loc.Longitude, 6))
+	}
+	return nil
+}
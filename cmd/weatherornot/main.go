@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/james-see/weatherornot/internal/api"
@@ -24,6 +31,11 @@ var (
 	hours        int
 	days         int
 	showLocation bool
+	noCache      bool
+	refresh      bool
+	offline      bool
+	timeout      time.Duration
+	pick         int
 )
 
 var rootCmd = &cobra.Command{
@@ -37,15 +49,20 @@ Location can be specified as:
   - City: "San Francisco" or "San Francisco,CA" or "San Francisco,CA,US"
   - Coordinates: "37.7749,-122.4194"
   - Favorite: Use -f or --favorite flag
+  - Current position: auto, or the here command
 
 If no location is provided, uses default_location from config.`,
 	Example: `  weatherornot 90210
   weatherornot "New York,NY"
   weatherornot "40.7128,-74.0060"
   weatherornot -f home
+  weatherornot Springfield --pick 2
   weatherornot --mode neofetch "London,GB"`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runWeather,
+
+	// Errors come with their own hints; the usage text would bury them
+	SilenceUsage: true,
 }
 
 func init() {
@@ -57,9 +74,15 @@ func init() {
 	rootCmd.PersistentFlags().IntVar(&hours, "hours", 12, "Number of hourly forecasts to show")
 	rootCmd.PersistentFlags().IntVar(&days, "days", 5, "Number of daily forecasts to show")
 	rootCmd.PersistentFlags().BoolVar(&showLocation, "show-location", true, "Show location name in output")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Neither read nor write the response cache")
+	rootCmd.PersistentFlags().BoolVar(&refresh, "refresh", false, "Ignore cached responses and fetch fresh data")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Never use the network; show the last known data")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "Give up on weather requests after this long")
+	rootCmd.Flags().IntVar(&pick, "pick", 0, "Use the Nth place when a city name matches several")
 
 	// Config subcommand
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(cacheCmd)
 }
 
 var configCmd = &cobra.Command{
@@ -83,11 +106,14 @@ var configInitCmd = &cobra.Command{
 		cfg := config.DefaultConfig()
 		
 		// Prompt for API key
-		fmt.Print("Enter your OpenWeatherMap API key: ")
+		fmt.Print("Enter your OpenWeatherMap API key (leave empty to use Open-Meteo): ")
 		fmt.Scanln(&cfg.APIKey)
+		if cfg.APIKey == "" {
+			cfg.Provider = api.ProviderOpenMeteo
+		}
 		
 		// Prompt for default location
-		fmt.Print("Enter default location (e.g., 10001 or San Francisco,CA): ")
+		fmt.Print("Enter default location (e.g., 10001, San Francisco,CA or auto): ")
 		var location string
 		fmt.Scanln(&location)
 		cfg.DefaultLocation = location
@@ -115,9 +141,34 @@ var configSetCmd = &cobra.Command{
 		key := args[0]
 		value := args[1]
 
+		// Endpoint overrides are set as endpoints.<name>
+		if name, ok := strings.CutPrefix(key, "endpoints."); ok {
+			if !isEndpoint(name) {
+				return fmt.Errorf("endpoint must be one of: %s", strings.Join(api.EndpointNames(), ", "))
+			}
+			if cfg.Endpoints == nil {
+				cfg.Endpoints = make(map[string]string)
+			}
+			if value == "" {
+				delete(cfg.Endpoints, name)
+			} else {
+				cfg.Endpoints[name] = value
+			}
+			if err := config.Save(cfg); err != nil {
+				return err
+			}
+			fmt.Printf("Set %s = %s\n", key, value)
+			return nil
+		}
+
 		switch key {
 		case "api_key":
 			cfg.APIKey = value
+		case "provider":
+			if !api.IsProvider(value) {
+				return fmt.Errorf("provider must be one of: %s", strings.Join(api.ProviderNames(), ", "))
+			}
+			cfg.Provider = value
 		case "default_location":
 			cfg.DefaultLocation = value
 		case "units":
@@ -130,6 +181,63 @@ var configSetCmd = &cobra.Command{
 				return fmt.Errorf("display_mode must be widget or neofetch")
 			}
 			cfg.DisplayMode = value
+		case "one_call":
+			boolVal, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("one_call must be true or false")
+			}
+			cfg.OneCall = boolVal
+		case "ensemble":
+			names, err := parseProviderList(value)
+			if err != nil {
+				return err
+			}
+			cfg.Ensemble = names
+		case "fallback_providers":
+			names, err := parseProviderList(value)
+			if err != nil {
+				return err
+			}
+			cfg.FallbackProviders = names
+		case "cache_current_ttl", "cache_forecast_ttl", "cache_geocode_ttl":
+			if _, err := time.ParseDuration(value); err != nil {
+				return fmt.Errorf("%s must be a duration such as 10m or 2h", key)
+			}
+			switch key {
+			case "cache_current_ttl":
+				cfg.CacheCurrentTTL = value
+			case "cache_forecast_ttl":
+				cfg.CacheForecastTTL = value
+			default:
+				cfg.CacheGeocodeTTL = value
+			}
+		case "gazetteer":
+			if value != api.GazetteerOff && value != api.GazetteerFallback && value != api.GazetteerPrimary {
+				return fmt.Errorf("gazetteer must be off, fallback or primary")
+			}
+			cfg.Gazetteer = value
+		case "here_source":
+			if value != api.PositionIP && value != api.PositionGPSD {
+				return fmt.Errorf("here_source must be ip or gpsd")
+			}
+			cfg.HereSource = value
+		case "here_ip_url":
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("here_ip_url must be an http or https URL")
+			}
+			cfg.HereIPURL = value
+		case "here_gpsd_address":
+			if _, _, err := net.SplitHostPort(value); err != nil {
+				return fmt.Errorf("here_gpsd_address must be host:port, e.g. %s", api.DefaultGPSDAddress)
+			}
+			cfg.HereGPSDAddress = value
+		case "here_ttl":
+			if _, err := time.ParseDuration(value); err != nil {
+				return fmt.Errorf("here_ttl must be a duration such as 30m or 2h")
+			}
+			cfg.HereTTL = value
+		case "user_agent":
+			cfg.UserAgent = value
 		case "show_colors":
 			boolVal, err := strconv.ParseBool(value)
 			if err != nil {
@@ -160,11 +268,38 @@ var configShowCmd = &cobra.Command{
 
 		fmt.Printf("API Key:          %s\n", maskAPIKey(cfg.APIKey))
 		fmt.Printf("Provider:         %s\n", cfg.Provider)
+		if strings.EqualFold(cfg.Provider, api.ProviderOpenWeatherMap) {
+			fmt.Printf("One Call 3.0:     %t\n", cfg.OneCall)
+		}
+		if len(cfg.FallbackProviders) > 0 {
+			fmt.Printf("Fallbacks:        %s\n", strings.Join(cfg.FallbackProviders, ", "))
+		}
+		if len(cfg.Ensemble) > 0 {
+			fmt.Printf("Ensemble:         %s\n", strings.Join(cfg.Ensemble, ", "))
+		}
 		fmt.Printf("Default Location: %s\n", cfg.DefaultLocation)
 		fmt.Printf("Units:            %s\n", cfg.Units)
 		fmt.Printf("Display Mode:     %s\n", cfg.DisplayMode)
 		fmt.Printf("Show Colors:      %t\n", cfg.ShowColors)
+		if cfg.UserAgent != "" {
+			fmt.Printf("User Agent:       %s\n", cfg.UserAgent)
+		}
+		fmt.Printf("Cache TTLs:       current %s, forecast %s, geocode %s\n",
+			cfg.CacheCurrentTTL, cfg.CacheForecastTTL, cfg.CacheGeocodeTTL)
+		fmt.Printf("Gazetteer:        %s\n", cfg.Gazetteer)
+		if strings.EqualFold(cfg.HereSource, api.PositionGPSD) {
+			fmt.Printf("Here:             gpsd at %s, cached %s\n", cfg.HereGPSDAddress, cfg.HereTTL)
+		} else {
+			fmt.Printf("Here:             %s, cached %s\n", cfg.HereIPURL, cfg.HereTTL)
+		}
 		
+		if len(cfg.Endpoints) > 0 {
+			fmt.Println("\nEndpoints:")
+			for name, u := range cfg.Endpoints {
+				fmt.Printf("  %s: %s\n", name, u)
+			}
+		}
+
 		if len(cfg.Favorites) > 0 {
 			fmt.Println("\nFavorites:")
 			for name, loc := range cfg.Favorites {
@@ -285,12 +420,6 @@ func runWeather(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Check if API key is set
-	if cfg.APIKey == "" {
-		fmt.Println("No API key configured. Please run 'weatherornot config init' or set api_key.")
-		return fmt.Errorf("API key not configured")
-	}
-
 	// Determine location
 	var locationStr string
 	if favorite != "" {
@@ -311,40 +440,60 @@ func runWeather(cmd *cobra.Command, args []string) error {
 	}
 
 	// Parse location
-	loc, err := location.Parse(locationStr)
+	parser := locationParser(cfg)
+	loc, err := parseLocationArg(cmd.Context(), cfg, locationStr)
 	if err != nil {
-		return fmt.Errorf("failed to parse location: %w", err)
+		return err
+	}
+	if pick < 0 {
+		return fmt.Errorf("--pick must be 1 or more")
 	}
 
 	// Override config with command line flags
-	if units != "" {
-		cfg.Units = units
-	}
-	if displayMode != "" {
-		cfg.DisplayMode = displayMode
-	}
-	if noColor {
-		cfg.ShowColors = false
+	applyFlags(cfg)
+
+	// Create weather provider selected in config
+	provider, done, err := newProvider(cfg)
+	if err != nil {
+		return err
 	}
+	defer done()
 
-	// Create API client
-	client := api.NewClient(cfg.APIKey, cfg.Units)
+	// Settle ambiguous city names first, which may mean asking the user
+	var place *api.Location
+	if loc.Type == location.TypeCity {
+		place, err = resolveCity(cmd.Context(), provider, loc)
+		if err != nil {
+			return withHint(withSuggestions(fmt.Errorf("failed to find location: %w", err), parser, locationStr))
+		}
+	}
 
 	// Fetch weather data based on location type
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
 	var weatherData *api.WeatherData
-	switch loc.Type {
-	case location.TypeZip:
-		weatherData, err = client.GetWeatherByZip(loc.Zip, loc.CountryCode)
-	case location.TypeCity:
-		weatherData, err = client.GetWeatherByCity(loc.City, loc.State, loc.Country)
-	case location.TypeCoords:
-		weatherData, err = client.GetWeatherByCoords(loc.Latitude, loc.Longitude)
-	default:
-		return fmt.Errorf("unsupported location type")
-	}
+	if place != nil {
+		weatherData, err = fetchWeatherAt(ctx, provider, place)
 
+		// Offline, the city may be cached under its name rather than the
+		// coordinates it resolved to
+		if errors.Is(err, api.ErrNoCachedData) {
+			weatherData, err = fetchWeather(ctx, provider, loc)
+		}
+	} else {
+		weatherData, err = fetchWeather(ctx, provider, loc)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to fetch weather data: %w", err)
+		return withHint(withSuggestions(fmt.Errorf("failed to fetch weather data: %w", err), parser, locationStr))
+	}
+	if favorite == "" && len(args) > 0 && !isAutoLocation(locationStr) {
+		rememberLocation(locationStr)
+	}
+
+	// Say which provider answered when the primary one failed
+	if primary := strings.Split(provider.Name(), ",")[0]; weatherData.Source != "" && weatherData.Source != primary {
+		fmt.Fprintf(os.Stderr, "Weather data provided by %s\n", weatherData.Source)
 	}
 
 	// Display weather data
@@ -376,6 +525,45 @@ func runWeather(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// parseProviderList parses a comma-separated list of provider names
+func parseProviderList(value string) ([]string, error) {
+	names := make([]string, 0)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !api.IsProvider(name) {
+			return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(api.ProviderNames(), ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// isEndpoint reports whether name is a known endpoint override
+func isEndpoint(name string) bool {
+	for _, n := range api.EndpointNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFlags overrides config values with command line flags
+func applyFlags(cfg *config.Config) {
+	if units != "" {
+		cfg.Units = units
+	}
+	if displayMode != "" {
+		cfg.DisplayMode = displayMode
+	}
+	if noColor {
+		cfg.ShowColors = false
+	}
+}
+
 func maskAPIKey(apiKey string) string {
 	if len(apiKey) <= 8 {
 		return strings.Repeat("*", len(apiKey))
@@ -384,7 +572,12 @@ func maskAPIKey(apiKey string) string {
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels requests that are in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/config"
+	"github.com/james-see/weatherornot/internal/display"
+	"github.com/james-see/weatherornot/internal/gazetteer"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+var parseCmd = &cobra.Command{
+	Use:   "parse <location>",
+	Short: "Show how a location would be read",
+	Long: `Show how weatherornot reads a location: its type, the parts it was split
+into and how sure the reading is. Cities are also looked up in the built-in
+gazetteer. Nothing is fetched from the network.`,
+	Example: `  weatherornot parse "Austin,Texas,USA"
+  weatherornot parse "K1A 0B1"
+  weatherornot parse "40°42'46\"N 74°0'22\"W"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runParse,
+}
+
+func init() {
+	rootCmd.AddCommand(parseCmd)
+}
+
+func runParse(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Favorites may be named bare or as @name
+	input := args[0]
+	if fav, exists := cfg.Favorites[strings.TrimPrefix(input, "@")]; exists {
+		fmt.Printf("Favorite:    %s = %s\n", input, fav)
+		input = fav
+	}
+	if isAutoLocation(input) {
+		printAutoLocation(cfg)
+		return nil
+	}
+
+	loc, err := parseLocationArg(cmd.Context(), cfg, input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Type:        %s\n", loc.Type)
+	switch loc.Type {
+	case location.TypeZip:
+		fmt.Printf("Postal code: %s\n", loc.Zip)
+		fmt.Printf("Country:     %s\n", loc.CountryCode)
+	case location.TypeCity:
+		fmt.Printf("City:        %s\n", loc.City)
+		if loc.State != "" {
+			fmt.Printf("State:       %s\n", loc.State)
+		}
+		if loc.Country != "" {
+			fmt.Printf("Country:     %s\n", loc.Country)
+		}
+	case location.TypeAirport:
+		fmt.Printf("Airport:     %s\n", loc.Name)
+		fmt.Printf("Country:     %s\n", loc.Country)
+		fmt.Printf("Coordinates: %.4f, %.4f\n", loc.Latitude, loc.Longitude)
+	case location.TypeCoords:
+		fmt.Printf("Coordinates: %.6f, %.6f\n", loc.Latitude, loc.Longitude)
+	}
+	fmt.Printf("Confidence:  %s (%.1f)\n", confidenceLabel(loc.Confidence), loc.Confidence)
+
+	if loc.Type == location.TypeCity {
+		matches := gazetteer.Search(loc.City, loc.State, loc.Country, 1)
+		if len(matches) == 0 {
+			fmt.Println("Gazetteer:   no match; the provider will be asked")
+		} else {
+			c := matches[0]
+			fmt.Printf("Gazetteer:   %s, %s, %s (%.4f, %.4f)\n", c.Name, c.Admin1, c.Country, c.Latitude, c.Longitude)
+		}
+	}
+	return nil
+}
+
+// printAutoLocation describes "auto" without looking the position up: the
+// configured source and the last position it gave, if any
+func printAutoLocation(cfg *config.Config) {
+	fmt.Println("Type:        current position")
+	if hereSource(cfg) == api.PositionGPSD {
+		fmt.Printf("Source:      gpsd at %s\n", cfg.HereGPSDAddress)
+	} else {
+		fmt.Printf("Source:      %s\n", cfg.HereIPURL)
+	}
+	if pos, storedAt, ok := cachedPosition(cfg); ok {
+		fmt.Printf("Last found:  %.6f, %.6f (%s)\n", pos.Latitude, pos.Longitude, display.FormatAge(time.Since(storedAt)))
+	} else {
+		fmt.Println("Last found:  not yet; it is looked up when weather is fetched")
+	}
+}
+
+// confidenceLabel names a parse confidence
+func confidenceLabel(confidence float64) string {
+	switch {
+	case confidence >= location.ConfidenceCertain:
+		return "certain"
+	case confidence >= location.ConfidenceInferred:
+		return "inferred"
+	case confidence >= location.ConfidenceLikely:
+		return "likely"
+	default:
+		return "guess"
+	}
+}
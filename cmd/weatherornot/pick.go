@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/config"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+// resolveCity finds the place a city name refers to. When the name
+// matches several places, the one to use comes from --pick, or is asked
+// for when stdin is a terminal. It returns nil without an error when the
+// places cannot be searched, leaving the city to a plain lookup.
+func resolveCity(ctx context.Context, provider api.Provider, loc *location.ParsedLocation) (*api.Location, error) {
+	// Only the search is timed; the user may take a while to choose
+	searchCtx, cancel := context.WithTimeout(ctx, timeout)
+	places, err := provider.SearchLocations(searchCtx, loc.City, loc.State, loc.Country)
+	cancel()
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) || ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return pickPlace(loc.City, places)
+}
+
+// fetchWeatherAt fetches weather for a resolved place and keeps its name
+func fetchWeatherAt(ctx context.Context, provider api.Provider, place *api.Location) (*api.WeatherData, error) {
+	data, err := provider.GetWeatherByCoords(ctx, place.Latitude, place.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	data.Location.Name = place.Name
+	data.Location.State = place.State
+	data.Location.Country = place.Country
+	return data, nil
+}
+
+// pickPlace chooses one of the places matching a city name
+func pickPlace(city string, places []api.Location) (*api.Location, error) {
+	if pick > len(places) {
+		return nil, fmt.Errorf("--pick %d is out of range, %q matches %d places", pick, city, len(places))
+	}
+	if pick > 0 {
+		return &places[pick-1], nil
+	}
+	if len(places) == 1 {
+		return &places[0], nil
+	}
+
+	if !isTerminal(os.Stdin) {
+		fmt.Fprintf(os.Stderr, "%q matches %d places, using %s; pass --pick N to choose another\n",
+			city, len(places), placeLabel(places[0]))
+		return &places[0], nil
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	fmt.Fprintf(os.Stderr, "%q matches several places:\n", city)
+	for i, p := range places {
+		fmt.Fprintf(os.Stderr, "  %d) %s (%.4f, %.4f)\n", i+1, placeLabel(p), p.Latitude, p.Longitude)
+	}
+
+	var place *api.Location
+	for place == nil {
+		fmt.Fprintf(os.Stderr, "Choose a place [1-%d, default 1]: ", len(places))
+		line, err := in.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" && err == nil {
+			answer = "1"
+		}
+
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(places) {
+			place = &places[n-1]
+		} else if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("no place chosen")
+			}
+			return nil, err
+		}
+	}
+
+	offerFavorite(in, place)
+	return place, nil
+}
+
+// offerFavorite asks for a name to save a chosen place under. The favorite
+// holds its exact coordinates, so it never needs to be chosen again.
+func offerFavorite(in *bufio.Reader, place *api.Location) {
+	fmt.Fprint(os.Stderr, "Save as a favorite? Enter a name, or leave empty to skip: ")
+	line, _ := in.ReadString('\n')
+	name := strings.TrimSpace(line)
+	if name == "" {
+		return
+	}
+
+	// Reload so flags applied to the running config are not saved
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not save favorite: %v\n", err)
+		return
+	}
+	if cfg.Favorites == nil {
+		cfg.Favorites = make(map[string]string)
+	}
+	cfg.Favorites[name] = fmt.Sprintf("%.4f,%.4f", place.Latitude, place.Longitude)
+
+	if err := config.Save(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not save favorite: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Added favorite: %s = %s\n", name, cfg.Favorites[name])
+}
+
+// placeLabel describes a place by name, state and country
+func placeLabel(p api.Location) string {
+	parts := []string{p.Name}
+	for _, part := range []string{p.State, p.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
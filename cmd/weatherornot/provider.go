@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/cache"
+	"github.com/james-see/weatherornot/internal/config"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+// newProvider builds the weather provider selected in config. With
+// fallback providers configured, the primary and its fallbacks form a
+// failover chain. Unless --no-cache is given, responses are cached on
+// disk and the last known data is shown when the network is down or
+// --offline is given. Places are resolved with the embedded gazetteer as
+// set in config. The returned function saves provider health and must be called
+// once the provider is no longer used.
+func newProvider(cfg *config.Config) (api.Provider, func(), error) {
+	if offline && (noCache || refresh) {
+		return nil, nil, fmt.Errorf("--offline cannot be combined with --no-cache or --refresh")
+	}
+
+	ttl, err := cacheTTL(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	cacheDir, _ := cache.Dir()
+
+	opts := api.ProviderOptions{
+		APIKey:     cfg.APIKey,
+		Units:      cfg.Units,
+		UserAgent:  cfg.UserAgent,
+		OneCall:    cfg.OneCall,
+		Members:    cfg.Ensemble,
+		Endpoints:  cfg.Endpoints,
+		GeocodeTTL: ttl.Geocode,
+		Refresh:    refresh,
+	}
+	// Providers keep their own caches only when the response cache is used
+	if !noCache {
+		opts.CacheDir = cacheDir
+	}
+
+	provider, done, err := newProviderChain(cfg, opts, cacheDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !noCache && cacheDir != "" {
+		cached := api.NewCached(provider, cache.New(filepath.Join(cacheDir, "responses")), ttl, cfg.Units)
+		cached.Refresh = refresh
+		cached.Offline = offline
+		provider = cached
+	}
+
+	switch strings.ToLower(cfg.Gazetteer) {
+	case api.GazetteerOff:
+	case api.GazetteerPrimary:
+		provider = api.NewLocalGeocoder(provider, true)
+	default:
+		provider = api.NewLocalGeocoder(provider, false)
+	}
+
+	return provider, done, nil
+}
+
+// fetchWeather fetches weather for a parsed location
+func fetchWeather(ctx context.Context, provider api.Provider, loc *location.ParsedLocation) (*api.WeatherData, error) {
+	switch loc.Type {
+	case location.TypeZip:
+		return provider.GetWeatherByZip(ctx, loc.Zip, loc.CountryCode)
+	case location.TypeCity:
+		return provider.GetWeatherByCity(ctx, loc.City, loc.State, loc.Country)
+	case location.TypeCoords:
+		data, err := provider.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		// Label the coordinates with the place they are in rather than
+		// the nearest weather station; without a match the station stays
+		if place, err := provider.ReverseGeocode(ctx, loc.Latitude, loc.Longitude); err == nil {
+			data.Location.Name = place.Name
+			data.Location.State = place.State
+			data.Location.Country = place.Country
+		}
+		return data, nil
+	case location.TypeAirport:
+		data, err := provider.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		data.Location.Name = loc.Name
+		data.Location.State = ""
+		data.Location.Country = loc.Country
+		return data, nil
+	}
+	return nil, fmt.Errorf("unsupported location type")
+}
+
+// newProviderChain builds the primary provider and its fallbacks. Provider
+// health is kept in cacheDir, which --no-cache does not affect.
+func newProviderChain(cfg *config.Config, opts api.ProviderOptions, cacheDir string) (api.Provider, func(), error) {
+	// Without an API key, fall back to a provider that needs no signup
+	primary := cfg.Provider
+	if cfg.APIKey == "" && strings.EqualFold(primary, api.ProviderOpenWeatherMap) {
+		primary = api.ProviderOpenMeteo
+	}
+
+	names := []string{primary}
+	for _, name := range cfg.FallbackProviders {
+		if !containsFold(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	chain := make([]api.Provider, 0, len(names))
+	for i, name := range names {
+		p, err := api.NewProvider(name, opts)
+		if err != nil {
+			// A misconfigured primary is an error; fallbacks are optional
+			if i == 0 {
+				return nil, nil, err
+			}
+			fmt.Fprintf(os.Stderr, "Skipping fallback provider %s: %v\n", name, err)
+			continue
+		}
+		chain = append(chain, p)
+	}
+
+	if len(chain) == 1 {
+		return chain[0], func() {}, nil
+	}
+
+	health := api.NewHealth()
+	if cacheDir != "" {
+		health = api.LoadHealth(filepath.Join(cacheDir, "health.json"))
+	}
+	failover := api.NewFailover(health, chain...)
+	failover.OnFailure = func(provider string, err error) {
+		fmt.Fprintf(os.Stderr, "Provider %s failed: %v\n", provider, err)
+	}
+
+	return failover, func() { health.Save() }, nil
+}
+
+// cacheTTL parses the cache lifetimes from config
+func cacheTTL(cfg *config.Config) (api.CacheTTL, error) {
+	var ttl api.CacheTTL
+	for _, setting := range []struct {
+		key   string
+		value string
+		dst   *time.Duration
+	}{
+		{"cache_current_ttl", cfg.CacheCurrentTTL, &ttl.Current},
+		{"cache_forecast_ttl", cfg.CacheForecastTTL, &ttl.Forecast},
+		{"cache_geocode_ttl", cfg.CacheGeocodeTTL, &ttl.Geocode},
+	} {
+		if setting.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(setting.value)
+		if err != nil {
+			return ttl, fmt.Errorf("invalid %s %q: %w", setting.key, setting.value, err)
+		}
+		*setting.dst = d
+	}
+	return ttl, nil
+}
+
+// containsFold reports whether names contains name, ignoring case
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/apitest"
+	"github.com/james-see/weatherornot/internal/config"
+)
+
+func TestNewProviderNoCache(t *testing.T) {
+	server := apitest.NewServer("")
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		noCache   bool
+		wantFiles bool
+	}{
+		{"cache", false, true},
+		{"no cache", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("XDG_CACHE_HOME", dir)
+			t.Setenv("HOME", dir)
+			noCache = tt.noCache
+			t.Cleanup(func() { noCache = false })
+
+			provider, done, err := newProvider(&config.Config{
+				APIKey:    "any",
+				Provider:  api.ProviderOpenWeatherMap,
+				Units:     "imperial",
+				Endpoints: server.Endpoints(),
+				Gazetteer: api.GazetteerOff,
+			})
+			if err != nil {
+				t.Fatalf("newProvider() error = %v", err)
+			}
+			if _, err := provider.GetWeatherByZip(context.Background(), "10001", "US"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			done()
+
+			var files []string
+			filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if tt.wantFiles != (len(files) > 0) {
+				t.Errorf("Expected files on disk: %v, got %v", tt.wantFiles, files)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/james-see/weatherornot/internal/cache"
+	"github.com/james-see/weatherornot/internal/config"
+	"github.com/james-see/weatherornot/internal/location"
+)
+
+const (
+	// recentKey is the cache key of the recently used locations
+	recentKey = "recent-locations"
+	// maxRecent is how many recent locations are remembered
+	maxRecent = 10
+)
+
+var (
+	recentOnce sync.Once
+	recent     []string
+)
+
+// recentLocations returns the locations most recently looked up, newest
+// first
+func recentLocations() []string {
+	recentOnce.Do(func() {
+		if dir, err := cache.Dir(); err == nil {
+			cache.New(dir).Load(recentKey, &recent)
+		}
+	})
+	return recent
+}
+
+// rememberLocation records input as the most recent location. Nothing is
+// written with --no-cache.
+func rememberLocation(input string) {
+	if noCache || strings.TrimSpace(input) == "" {
+		return
+	}
+
+	list := []string{input}
+	for _, r := range recentLocations() {
+		if !strings.EqualFold(r, input) && len(list) < maxRecent {
+			list = append(list, r)
+		}
+	}
+	recent = list
+
+	if dir, err := cache.Dir(); err == nil {
+		// Failing to remember is not worth failing the command for
+		_ = cache.New(dir).Put(recentKey, list)
+	}
+}
+
+// locationParser returns a parser that suggests favorites and recent
+// locations when input cannot be read
+func locationParser(cfg *config.Config) location.Parser {
+	names := make([]string, 0, len(cfg.Favorites))
+	for name := range cfg.Favorites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	known := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		known = append(known, "@"+name)
+	}
+	for _, name := range names {
+		known = append(known, cfg.Favorites[name])
+	}
+	known = append(known, recentLocations()...)
+	return location.Parser{Known: known}
+}
@@ -0,0 +1,407 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/cache"
+)
+
+// ErrNoCachedData is returned in offline mode for locations that have
+// never been fetched
+var ErrNoCachedData = errors.New("no cached weather data for this location")
+
+// CacheTTL sets how long each kind of response is reused
+type CacheTTL struct {
+	Current  time.Duration
+	Forecast time.Duration
+	Geocode  time.Duration
+}
+
+// DefaultCacheTTL is used for any CacheTTL value left at zero
+var DefaultCacheTTL = CacheTTL{
+	Current:  10 * time.Minute,
+	Forecast: time.Hour,
+	Geocode:  30 * 24 * time.Hour,
+}
+
+// Cached wraps a provider and reuses its responses from an on-disk cache.
+// Responses are keyed by provider, normalized location and units, and
+// current conditions, forecasts and geocoding results expire separately.
+type Cached struct {
+	provider Provider
+	store    *cache.Cache
+	ttl      CacheTTL
+	units    string
+
+	// Refresh ignores cached responses but still stores fresh ones
+	Refresh bool
+
+	// Offline never contacts the provider and serves the last known data
+	// regardless of its age
+	Offline bool
+}
+
+// NewCached creates a caching wrapper around provider
+func NewCached(provider Provider, store *cache.Cache, ttl CacheTTL, units string) *Cached {
+	if ttl.Current <= 0 {
+		ttl.Current = DefaultCacheTTL.Current
+	}
+	if ttl.Forecast <= 0 {
+		ttl.Forecast = DefaultCacheTTL.Forecast
+	}
+	if ttl.Geocode <= 0 {
+		ttl.Geocode = DefaultCacheTTL.Geocode
+	}
+	return &Cached{
+		provider: provider,
+		store:    store,
+		ttl:      ttl,
+		units:    units,
+	}
+}
+
+// Name returns the name of the wrapped provider
+func (c *Cached) Name() string {
+	return c.provider.Name()
+}
+
+// GetCurrent fetches current weather by coordinates
+func (c *Cached) GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	key := c.weatherKey("current", coordKey(lat, lon))
+
+	if c.Offline {
+		return c.offline(key)
+	}
+
+	var data WeatherData
+	if c.get(key, c.ttl.Current, &data) {
+		return &data, nil
+	}
+
+	fresh, err := c.provider.GetCurrent(ctx, lat, lon)
+	if err != nil {
+		return c.fallback(key, err)
+	}
+	c.put(key, currentPart(fresh))
+	return fresh, nil
+}
+
+// GetForecast fetches forecast data
+func (c *Cached) GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	key := c.weatherKey("forecast", coordKey(lat, lon))
+
+	if c.Offline {
+		return c.offline(key)
+	}
+
+	var data WeatherData
+	if c.get(key, c.ttl.Forecast, &data) {
+		return &data, nil
+	}
+
+	fresh, err := c.provider.GetForecast(ctx, lat, lon)
+	if err != nil {
+		return c.fallback(key, err)
+	}
+	c.put(key, forecastPart(fresh))
+	return fresh, nil
+}
+
+// Geocode converts city name to coordinates
+func (c *Cached) Geocode(ctx context.Context, city, state, country string) (float64, float64, error) {
+	key := c.locationKey("geocode", normalizePlace(city, state, country))
+
+	var loc Location
+	if c.get(key, c.ttl.Geocode, &loc) {
+		return loc.Latitude, loc.Longitude, nil
+	}
+	if c.Offline {
+		return 0, 0, ErrNoCachedData
+	}
+
+	lat, lon, err := c.provider.Geocode(ctx, city, state, country)
+	if err != nil {
+		return 0, 0, err
+	}
+	c.put(key, Location{Latitude: lat, Longitude: lon})
+	return lat, lon, nil
+}
+
+// SearchLocations returns the places matching a city name
+func (c *Cached) SearchLocations(ctx context.Context, city, state, country string) ([]Location, error) {
+	key := c.locationKey("search", normalizePlace(city, state, country))
+
+	var locs []Location
+	if c.get(key, c.ttl.Geocode, &locs) {
+		return locs, nil
+	}
+	if c.Offline {
+		return nil, ErrNoCachedData
+	}
+
+	locs, err := c.provider.SearchLocations(ctx, city, state, country)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, locs)
+	return locs, nil
+}
+
+// ReverseGeocode returns the place at coordinates
+func (c *Cached) ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	key := c.locationKey("reverse", coordKey(lat, lon))
+
+	var loc Location
+	if c.get(key, c.ttl.Geocode, &loc) {
+		return &loc, nil
+	}
+	if c.Offline {
+		return nil, ErrNoCachedData
+	}
+
+	fresh, err := c.provider.ReverseGeocode(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, fresh)
+	return fresh, nil
+}
+
+// GetWeatherByZip fetches weather data by zip code
+func (c *Cached) GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error) {
+	return c.byPlace(ctx, "zip:"+normalizePlace(zip, countryCode), func() (*WeatherData, error) {
+		return c.provider.GetWeatherByZip(ctx, zip, countryCode)
+	})
+}
+
+// GetWeatherByCity fetches weather data by city name
+func (c *Cached) GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error) {
+	return c.byPlace(ctx, "city:"+normalizePlace(city, state, country), func() (*WeatherData, error) {
+		return c.provider.GetWeatherByCity(ctx, city, state, country)
+	})
+}
+
+// GetWeatherByCoords fetches weather data by coordinates. When it cannot
+// be fetched, the last known data for the coordinates is returned instead.
+func (c *Cached) GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	if c.Offline {
+		if data, ok := c.lastKnown(lat, lon); ok {
+			return data, nil
+		}
+		return nil, ErrNoCachedData
+	}
+
+	data, err := c.weatherByCoords(ctx, lat, lon)
+	if err != nil {
+		if last, ok := c.lastKnown(lat, lon); ok && IsUnavailable(err) {
+			return last, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// weatherByCoords fetches weather data by coordinates, fetching only the
+// parts that are missing from the cache or have expired
+func (c *Cached) weatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	var current, forecast WeatherData
+	haveCurrent := c.get(c.weatherKey("current", coordKey(lat, lon)), c.ttl.Current, &current)
+	haveForecast := c.get(c.weatherKey("forecast", coordKey(lat, lon)), c.ttl.Forecast, &forecast)
+
+	switch {
+	case haveCurrent && haveForecast:
+		return mergeParts(&current, &forecast), nil
+	case haveCurrent:
+		fresh, err := c.provider.GetForecast(ctx, lat, lon)
+		if err != nil {
+			return nil, err
+		}
+		c.put(c.weatherKey("forecast", coordKey(lat, lon)), forecastPart(fresh))
+		return mergeParts(&current, fresh), nil
+	case haveForecast:
+		fresh, err := c.provider.GetCurrent(ctx, lat, lon)
+		if err != nil {
+			return nil, err
+		}
+		c.put(c.weatherKey("current", coordKey(lat, lon)), currentPart(fresh))
+		return mergeParts(fresh, &forecast), nil
+	}
+
+	data, err := c.provider.GetWeatherByCoords(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	c.putParts(lat, lon, data)
+	return data, nil
+}
+
+// byPlace fetches weather for a named place. Once the place has been
+// resolved, its coordinates are cached so later runs only need the
+// weather itself, and so the place can be shown offline.
+func (c *Cached) byPlace(ctx context.Context, place string, fetch func() (*WeatherData, error)) (*WeatherData, error) {
+	key := c.locationKey("location", place)
+
+	var loc Location
+	if c.get(key, c.ttl.Geocode, &loc) {
+		data, err := c.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		data.Location = loc
+		return data, nil
+	}
+	if c.Offline {
+		return nil, ErrNoCachedData
+	}
+
+	data, err := fetch()
+	if err != nil {
+		// The place may still be known from an earlier run
+		if _, ok := c.store.Load(key, &loc); ok && IsUnavailable(err) {
+			if last, ok := c.lastKnown(loc.Latitude, loc.Longitude); ok {
+				last.Location = loc
+				return last, nil
+			}
+		}
+		return nil, err
+	}
+	c.put(key, data.Location)
+	c.putParts(data.Location.Latitude, data.Location.Longitude, data)
+	return data, nil
+}
+
+// lastKnown returns the most recent cached weather at coordinates,
+// regardless of its age, marked as stale
+func (c *Cached) lastKnown(lat, lon float64) (*WeatherData, bool) {
+	var current, forecast WeatherData
+	if _, ok := c.store.Load(c.weatherKey("current", coordKey(lat, lon)), &current); !ok {
+		return nil, false
+	}
+	c.store.Load(c.weatherKey("forecast", coordKey(lat, lon)), &forecast)
+
+	data := mergeParts(&current, &forecast)
+	data.Stale = true
+	return data, true
+}
+
+// offline returns the cached value under key regardless of its age
+func (c *Cached) offline(key string) (*WeatherData, error) {
+	var data WeatherData
+	if _, ok := c.store.Load(key, &data); !ok {
+		return nil, ErrNoCachedData
+	}
+	data.Stale = true
+	return &data, nil
+}
+
+// fallback returns the cached value under key when the provider is
+// unavailable, or the fetch error when nothing is cached
+func (c *Cached) fallback(key string, err error) (*WeatherData, error) {
+	if !IsUnavailable(err) {
+		return nil, err
+	}
+	if data, cacheErr := c.offline(key); cacheErr == nil {
+		return data, nil
+	}
+	return nil, err
+}
+
+// get decodes a cached value younger than maxAge into v. In offline mode
+// the age does not matter.
+func (c *Cached) get(key string, maxAge time.Duration, v interface{}) bool {
+	if c.Offline {
+		_, ok := c.store.Load(key, v)
+		return ok
+	}
+	if c.Refresh {
+		return false
+	}
+	return c.store.Get(key, maxAge, v)
+}
+
+// put caches v under key. Caching is best effort, so errors are ignored.
+// Weather data is stored without the provider that served it: a fallback
+// that answered once says nothing about a later run replaying the data.
+func (c *Cached) put(key string, v interface{}) {
+	if data, ok := v.(*WeatherData); ok {
+		if data.FetchedAt.IsZero() {
+			data.FetchedAt = time.Now()
+		}
+		data.Source = ""
+	}
+	c.store.Put(key, v)
+}
+
+// putParts caches the current conditions and forecast of data separately
+func (c *Cached) putParts(lat, lon float64, data *WeatherData) {
+	c.put(c.weatherKey("current", coordKey(lat, lon)), currentPart(data))
+	c.put(c.weatherKey("forecast", coordKey(lat, lon)), forecastPart(data))
+}
+
+// weatherKey builds the cache key of a weather response
+func (c *Cached) weatherKey(kind, place string) string {
+	return fmt.Sprintf("%s|%s|%s|%s", strings.ToLower(c.provider.Name()), kind, place, c.units)
+}
+
+// locationKey builds the cache key of a geocoding result, which does not
+// depend on units
+func (c *Cached) locationKey(kind, place string) string {
+	return fmt.Sprintf("%s|%s|%s", strings.ToLower(c.provider.Name()), kind, place)
+}
+
+// currentPart returns the short-lived part of data
+func currentPart(data *WeatherData) *WeatherData {
+	return &WeatherData{
+		Location: data.Location,
+		Current:  data.Current,
+		Minutely: data.Minutely,
+		Alerts:   data.Alerts,
+		Source:   data.Source,
+		Sources:  data.Sources,
+
+		FetchedAt: data.FetchedAt,
+		Stale:     data.Stale,
+	}
+}
+
+// forecastPart returns the hourly and daily forecasts of data
+func forecastPart(data *WeatherData) *WeatherData {
+	return &WeatherData{
+		Hourly:    data.Hourly,
+		Daily:     data.Daily,
+		FetchedAt: data.FetchedAt,
+		Stale:     data.Stale,
+	}
+}
+
+// mergeParts combines cached current conditions and forecast
+func mergeParts(current, forecast *WeatherData) *WeatherData {
+	data := currentPart(current)
+	data.Hourly = forecast.Hourly
+	data.Daily = forecast.Daily
+
+	// The data is as old as its oldest part
+	if forecast.FetchedAt.Before(data.FetchedAt) && !forecast.FetchedAt.IsZero() {
+		data.FetchedAt = forecast.FetchedAt
+	}
+	data.Stale = current.Stale || forecast.Stale
+	return data
+}
+
+// coordKey normalizes coordinates to about 100 m so nearby lookups share
+// cache entries
+func coordKey(lat, lon float64) string {
+	return fmt.Sprintf("%.3f,%.3f", lat, lon)
+}
+
+// normalizePlace normalizes the parts of a place name for use in a key
+func normalizePlace(parts ...string) string {
+	for i, p := range parts {
+		parts[i] = strings.ToLower(strings.Join(strings.Fields(p), " "))
+	}
+	return strings.Join(parts, ",")
+}
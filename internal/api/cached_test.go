@@ -0,0 +1,103 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/apitest"
+	"github.com/james-see/weatherornot/internal/cache"
+)
+
+func TestCachedReusesResponses(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: server.Endpoints()})
+	cached := api.NewCached(client, cache.New(t.TempDir()), api.CacheTTL{}, "imperial")
+
+	first, err := cached.GetWeatherByCity(ctx, "New York", "NY", "US")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	requests := len(server.Requests())
+
+	second, err := cached.GetWeatherByCity(ctx, "  new york", "ny", "us")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := len(server.Requests()); got != requests {
+		t.Errorf("Expected no new requests, got %d", got-requests)
+	}
+	if second.Location.Name != first.Location.Name || len(second.Hourly) != len(first.Hourly) {
+		t.Errorf("Cached data differs from fresh data")
+	}
+
+	cached.Refresh = true
+	if _, err := cached.GetWeatherByCity(ctx, "New York", "NY", "US"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := len(server.Requests()); got == requests {
+		t.Error("Expected refresh to fetch again")
+	}
+}
+
+func TestCachedExpiresCurrentSeparately(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: server.Endpoints()})
+	ttl := api.CacheTTL{Current: time.Nanosecond, Forecast: time.Hour}
+	cached := api.NewCached(client, cache.New(t.TempDir()), ttl, "imperial")
+
+	if _, err := cached.GetWeatherByCoords(ctx, 40.7128, -74.006); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	before := len(server.Requests())
+
+	if _, err := cached.GetWeatherByCoords(ctx, 40.7128, -74.006); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	requests := server.Requests()[before:]
+	if len(requests) != 1 || requests[0] != "/weather" {
+		t.Errorf("Expected only /weather to be refetched, got %v", requests)
+	}
+}
+
+func TestCachedFallsBackToLastKnownData(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: server.Endpoints()})
+	store := cache.New(t.TempDir())
+	cached := api.NewCached(client, store, api.CacheTTL{}, "imperial")
+
+	if _, err := cached.GetWeatherByCity(ctx, "New York", "NY", "US"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.Close()
+
+	cached.Refresh = true
+	data, err := cached.GetWeatherByCity(ctx, "New York", "NY", "US")
+	if err != nil {
+		t.Fatalf("Expected last known data, got error: %v", err)
+	}
+	if !data.Stale || data.FetchedAt.IsZero() {
+		t.Errorf("Expected stale data with fetch time, got stale=%t fetched=%v", data.Stale, data.FetchedAt)
+	}
+	if data.Location.Name != "New York" || len(data.Hourly) == 0 {
+		t.Errorf("Expected full last known data, got %+v", data.Location)
+	}
+
+	offline := api.NewCached(client, store, api.CacheTTL{}, "imperial")
+	offline.Offline = true
+	if data, err := offline.GetWeatherByZip(ctx, "10001", "US"); err != api.ErrNoCachedData {
+		t.Errorf("Expected ErrNoCachedData for unknown place, got %v, %v", data, err)
+	}
+	if data, err := offline.GetWeatherByCity(ctx, "new york", "ny", "us"); err != nil || !data.Stale {
+		t.Errorf("Expected stale data offline, got %v", err)
+	}
+}
@@ -1,159 +1,190 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"time"
 )
 
 const (
-	baseURL         = "https://api.openweathermap.org/data/2.5"
-	geocodingURL    = "https://api.openweathermap.org/geo/1.0"
+	openWeatherMapURL          = "https://api.openweathermap.org/data/2.5"
+	openWeatherMapGeocodingURL = "https://api.openweathermap.org/geo/1.0"
+
+	// geocodeCandidates is how many places are requested when resolving a
+	// city name, so ambiguous names can be told apart
+	geocodeCandidates = 5
 )
 
-// Client represents an OpenWeatherMap API client
+// ProviderOpenWeatherMap is the registry name of the OpenWeatherMap provider
+const ProviderOpenWeatherMap = "openweathermap"
+
+func init() {
+	RegisterProvider(ProviderOpenWeatherMap, func(opts ProviderOptions) (Provider, error) {
+		if opts.APIKey == "" {
+			return nil, fmt.Errorf("OpenWeatherMap requires an API key")
+		}
+		return NewClientWithOptions(opts), nil
+	})
+}
+
+// Client represents an OpenWeatherMap API client and implements Provider
 type Client struct {
-	apiKey     string
-	httpClient *http.Client
-	units      string
+	apiKey  string
+	http    *fetcher
+	units   string
+	oneCall bool
+
+	baseURL      string
+	oneCallURL   string
+	geocodingURL string
+
+	geocodes *geocodeCache
 }
 
 // NewClient creates a new API client
 func NewClient(apiKey, units string) *Client {
+	return NewClientWithOptions(ProviderOptions{APIKey: apiKey, Units: units})
+}
+
+// NewClientWithOptions creates a new API client from provider options
+func NewClientWithOptions(opts ProviderOptions) *Client {
 	return &Client{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		units: units,
+		apiKey:  opts.APIKey,
+		http:    newFetcher(opts.UserAgent),
+		units:   opts.Units,
+		oneCall: opts.OneCall,
+
+		baseURL:      opts.endpoint(EndpointOpenWeatherMap, openWeatherMapURL),
+		oneCallURL:   opts.endpoint(EndpointOpenWeatherMapOneCall, openWeatherMapOneCallURL),
+		geocodingURL: opts.endpoint(EndpointOpenWeatherMapGeo, openWeatherMapGeocodingURL),
+
+		geocodes: newGeocodeCache(ProviderOpenWeatherMap, opts.CacheDir, opts.GeocodeTTL, opts.Refresh),
 	}
 }
 
-// GetWeatherByZip fetches weather data by zip code
-func (c *Client) GetWeatherByZip(zip, countryCode string) (*WeatherData, error) {
-	// First get current weather
-	currentURL := fmt.Sprintf("%s/weather?zip=%s,%s&appid=%s&units=%s", 
-		baseURL, zip, countryCode, c.apiKey, c.units)
-	
-	current, err := c.fetchCurrentWeather(currentURL)
-	if err != nil {
-		return nil, err
-	}
+// Name returns the registry name of the provider
+func (c *Client) Name() string {
+	return ProviderOpenWeatherMap
+}
 
-	// Get forecast using coordinates
-	forecast, err := c.GetForecast(current.Location.Latitude, current.Location.Longitude)
-	if err != nil {
-		return nil, err
+// GetCurrent fetches current weather by coordinates
+func (c *Client) GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	if c.oneCall {
+		return c.fetchOneCall(ctx, lat, lon, "minutely,hourly,daily")
 	}
 
-	return &WeatherData{
-		Current:  current.Current,
-		Location: current.Location,
-		Hourly:   forecast.Hourly,
-		Daily:    forecast.Daily,
-	}, nil
+	currentURL := fmt.Sprintf("%s/weather?lat=%f&lon=%f&appid=%s&units=%s", 
+		c.baseURL, lat, lon, c.apiKey, c.units)
+
+	return c.fetchCurrentWeather(ctx, currentURL)
 }
 
-// GetWeatherByCity fetches weather data by city name
-func (c *Client) GetWeatherByCity(city, state, country string) (*WeatherData, error) {
-	// Build query string
-	query := city
-	if state != "" {
-		query += "," + state
+// GetWeatherByZip fetches weather data by zip code. The zip code is
+// resolved with the geocoding API, and the weather is then fetched by
+// coordinates, current conditions and forecast at once.
+func (c *Client) GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error) {
+	loc, err := c.geocodeZip(ctx, zip, countryCode)
+	if err != nil {
+		return nil, err
 	}
-	if country != "" {
-		query += "," + country
+	if c.oneCall {
+		return c.oneCallAt(ctx, loc)
 	}
+	return c.weatherAt(ctx, loc)
+}
 
-	currentURL := fmt.Sprintf("%s/weather?q=%s&appid=%s&units=%s", 
-		baseURL, url.QueryEscape(query), c.apiKey, c.units)
-	
-	current, err := c.fetchCurrentWeather(currentURL)
+// GetWeatherByCity fetches weather data by city name. The city is
+// resolved with the geocoding API, which honours the state, and the
+// weather is then fetched by coordinates.
+func (c *Client) GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error) {
+	loc, err := c.geocodeCity(ctx, city, state, country)
 	if err != nil {
 		return nil, err
 	}
+	if c.oneCall {
+		return c.oneCallAt(ctx, loc)
+	}
+	return c.weatherAt(ctx, loc)
+}
 
-	// Get forecast using coordinates
-	forecast, err := c.GetForecast(current.Location.Latitude, current.Location.Longitude)
-	if err != nil {
-		return nil, err
+// GetWeatherByCoords fetches weather data by coordinates. Current
+// conditions and forecast are fetched concurrently.
+func (c *Client) GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	if c.oneCall {
+		return c.fetchOneCall(ctx, lat, lon, "")
 	}
 
-	return &WeatherData{
-		Current:  current.Current,
-		Location: current.Location,
-		Hourly:   forecast.Hourly,
-		Daily:    forecast.Daily,
-	}, nil
-}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-// GetWeatherByCoords fetches weather data by coordinates
-func (c *Client) GetWeatherByCoords(lat, lon float64) (*WeatherData, error) {
-	currentURL := fmt.Sprintf("%s/weather?lat=%f&lon=%f&appid=%s&units=%s", 
-		baseURL, lat, lon, c.apiKey, c.units)
-	
-	current, err := c.fetchCurrentWeather(currentURL)
+	var forecast *WeatherData
+	var forecastErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		forecast, forecastErr = c.GetForecast(ctx, lat, lon)
+	}()
+
+	current, err := c.GetCurrent(ctx, lat, lon)
 	if err != nil {
+		// No point waiting for a forecast that will not be shown
+		cancel()
+		<-done
 		return nil, err
 	}
 
-	// Get forecast using coordinates
-	forecast, err := c.GetForecast(lat, lon)
-	if err != nil {
-		return nil, err
+	<-done
+	if forecastErr != nil {
+		return nil, forecastErr
 	}
 
-	return &WeatherData{
-		Current:  current.Current,
-		Location: current.Location,
-		Hourly:   forecast.Hourly,
-		Daily:    forecast.Daily,
-	}, nil
+	return combine(current, forecast), nil
 }
 
-// fetchCurrentWeather fetches current weather from the API
-func (c *Client) fetchCurrentWeather(apiURL string) (*WeatherData, error) {
-	resp, err := c.httpClient.Get(apiURL)
+// weatherAt fetches weather for a geocoded location and keeps its name
+func (c *Client) weatherAt(ctx context.Context, loc *Location) (*WeatherData, error) {
+	data, err := c.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching weather data: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
+	data.Location.Name = loc.Name
+	data.Location.State = loc.State
+	data.Location.Country = loc.Country
+	return data, nil
+}
 
+// fetchCurrentWeather fetches current weather from the API
+func (c *Client) fetchCurrentWeather(ctx context.Context, apiURL string) (*WeatherData, error) {
 	var owmResp OpenWeatherMapResponse
-	if err := json.NewDecoder(resp.Body).Decode(&owmResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if err := c.http.getJSON(ctx, apiURL, &owmResp); err != nil {
+		return nil, fmt.Errorf("error fetching weather data: %w", err)
 	}
 
 	return c.parseCurrentWeather(&owmResp), nil
 }
 
 // GetForecast fetches forecast data
-func (c *Client) GetForecast(lat, lon float64) (*WeatherData, error) {
-	forecastURL := fmt.Sprintf("%s/forecast?lat=%f&lon=%f&appid=%s&units=%s", 
-		baseURL, lat, lon, c.apiKey, c.units)
-
-	resp, err := c.httpClient.Get(forecastURL)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching forecast data: %w", err)
+func (c *Client) GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	if c.oneCall {
+		data, err := c.fetchOneCall(ctx, lat, lon, "current")
+		if err != nil {
+			return nil, err
+		}
+		return &WeatherData{
+			Hourly:   data.Hourly,
+			Daily:    data.Daily,
+			Minutely: data.Minutely,
+			Alerts:   data.Alerts,
+		}, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
-	}
+	forecastURL := fmt.Sprintf("%s/forecast?lat=%f&lon=%f&appid=%s&units=%s", 
+		c.baseURL, lat, lon, c.apiKey, c.units)
 
 	var forecastResp OpenWeatherMapForecastResponse
-	if err := json.NewDecoder(resp.Body).Decode(&forecastResp); err != nil {
-		return nil, fmt.Errorf("error decoding forecast response: %w", err)
+	if err := c.http.getJSON(ctx, forecastURL, &forecastResp); err != nil {
+		return nil, fmt.Errorf("error fetching forecast data: %w", err)
 	}
 
 	return c.parseForecast(&forecastResp), nil
@@ -301,7 +332,17 @@ func (c *Client) parseForecast(resp *OpenWeatherMapForecastResponse) *WeatherDat
 }
 
 // Geocode converts city name to coordinates
-func (c *Client) Geocode(city, state, country string) (float64, float64, error) {
+func (c *Client) Geocode(ctx context.Context, city, state, country string) (float64, float64, error) {
+	loc, err := c.geocodeCity(ctx, city, state, country)
+	if err != nil {
+		return 0, 0, err
+	}
+	return loc.Latitude, loc.Longitude, nil
+}
+
+// SearchLocations returns the places matching a city name with the
+// OpenWeatherMap geocoding API
+func (c *Client) SearchLocations(ctx context.Context, city, state, country string) ([]Location, error) {
 	query := city
 	if state != "" {
 		query += "," + state
@@ -310,29 +351,78 @@ func (c *Client) Geocode(city, state, country string) (float64, float64, error)
 		query += "," + country
 	}
 
-	geocodeURL := fmt.Sprintf("%s/direct?q=%s&limit=1&appid=%s", 
-		geocodingURL, url.QueryEscape(query), c.apiKey)
+	geocodeURL := fmt.Sprintf("%s/direct?q=%s&limit=%d&appid=%s", 
+		c.geocodingURL, url.QueryEscape(query), geocodeCandidates, c.apiKey)
 
-	resp, err := c.httpClient.Get(geocodeURL)
-	if err != nil {
-		return 0, 0, fmt.Errorf("error geocoding location: %w", err)
+	var geoResp GeocodingResponse
+	if err := c.http.getJSON(ctx, geocodeURL, &geoResp); err != nil {
+		return nil, fmt.Errorf("error geocoding location: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return 0, 0, fmt.Errorf("geocoding API error (status %d): %s", resp.StatusCode, string(body))
+	if len(geoResp) == 0 {
+		return nil, ErrNotFound
 	}
 
+	locs := make([]Location, 0, len(geoResp))
+	for _, r := range geoResp {
+		locs = append(locs, Location{
+			Name:      r.Name,
+			State:     r.State,
+			Country:   r.Country,
+			Latitude:  r.Lat,
+			Longitude: r.Lon,
+		})
+	}
+	return uniqueLocations(locs), nil
+}
+
+// ReverseGeocode returns the place at coordinates with the OpenWeatherMap
+// geocoding API
+func (c *Client) ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	reverseURL := fmt.Sprintf("%s/reverse?lat=%f&lon=%f&limit=1&appid=%s",
+		c.geocodingURL, lat, lon, c.apiKey)
+
 	var geoResp GeocodingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&geoResp); err != nil {
-		return 0, 0, fmt.Errorf("error decoding geocoding response: %w", err)
+	if err := c.http.getJSON(ctx, reverseURL, &geoResp); err != nil {
+		return nil, fmt.Errorf("error reverse geocoding location: %w", err)
 	}
 
 	if len(geoResp) == 0 {
-		return 0, 0, fmt.Errorf("location not found")
+		return nil, ErrNotFound
 	}
 
-	return geoResp[0].Lat, geoResp[0].Lon, nil
+	return &Location{
+		Name:      geoResp[0].Name,
+		State:     geoResp[0].State,
+		Country:   geoResp[0].Country,
+		Latitude:  lat,
+		Longitude: lon,
+	}, nil
 }
 
+// geocodeCity resolves a city name to its best match
+func (c *Client) geocodeCity(ctx context.Context, city, state, country string) (*Location, error) {
+	place := cityPlace(city, state, country)
+	if loc, ok := c.geocodes.get(place); ok {
+		return loc, nil
+	}
+
+	locs, err := c.SearchLocations(ctx, city, state, country)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := locs[0]
+	c.geocodes.put(place, loc)
+	return &loc, nil
+}
+
+// zipPlace is the geocoding cache key of a zip code
+func zipPlace(zip, countryCode string) string {
+	return "zip:" + normalizePlace(zip, countryCode)
+}
+
+// cityPlace is the geocoding cache key of a city
+func cityPlace(city, state, country string) string {
+	return "city:" + normalizePlace(city, state, country)
+}
@@ -0,0 +1,202 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/apitest"
+)
+
+func TestClientAgainstFakeServer(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("test-key")
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{
+		APIKey:    "test-key",
+		Units:     "imperial",
+		Endpoints: server.Endpoints(),
+	})
+
+	tests := []struct {
+		name  string
+		fetch func() (*api.WeatherData, error)
+	}{
+		{"zip", func() (*api.WeatherData, error) { return client.GetWeatherByZip(ctx, "10001", "US") }},
+		{"city", func() (*api.WeatherData, error) { return client.GetWeatherByCity(ctx, "New York", "NY", "US") }},
+		{"coords", func() (*api.WeatherData, error) { return client.GetWeatherByCoords(ctx, 40.7128, -74.006) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.fetch()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if data.Location.Name != "New York" {
+				t.Errorf("Expected New York, got %q", data.Location.Name)
+			}
+			if data.Current.Temperature != 68.4 {
+				t.Errorf("Expected temperature 68.4, got %.1f", data.Current.Temperature)
+			}
+			if len(data.Hourly) != 16 {
+				t.Errorf("Expected 16 hourly forecasts, got %d", len(data.Hourly))
+			}
+			if len(data.Daily) == 0 || len(data.Daily) > 5 {
+				t.Errorf("Expected 1-5 daily forecasts, got %d", len(data.Daily))
+			}
+		})
+	}
+}
+
+func TestClientGeocodeAgainstFakeServer(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: server.Endpoints()})
+
+	lat, lon, err := client.Geocode(ctx, "New York", "NY", "US")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lat < 40.7 || lat > 40.8 || lon < -74.1 || lon > -74.0 {
+		t.Errorf("Unexpected coordinates %f,%f", lat, lon)
+	}
+
+	if _, _, err := client.Geocode(ctx, apitest.UnknownCity, "", ""); err == nil {
+		t.Error("Expected error for unknown city")
+	}
+	if _, err := client.GetWeatherByCity(ctx, apitest.UnknownCity, "", ""); err == nil {
+		t.Error("Expected error for unknown city")
+	}
+}
+
+func TestClientRejectedAPIKey(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("right-key")
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "wrong-key", Endpoints: server.Endpoints()})
+
+	if _, err := client.GetWeatherByCoords(ctx, 40.7128, -74.006); err == nil {
+		t.Error("Expected error for rejected API key")
+	}
+}
+
+func TestClientZipWithSpace(t *testing.T) {
+	var zips []string
+	handler := apitest.NewHandler("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if zip := r.URL.Query().Get("zip"); zip != "" {
+			zips = append(zips, r.URL.Path+"?"+zip)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: apitest.Endpoints(server.URL)})
+	if _, err := client.GetWeatherByZip(context.Background(), "K1A 0B1", "CA"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The zip is only geocoded; the weather is fetched by coordinates
+	if len(zips) != 1 || zips[0] != "/geo/1.0/zip?K1A 0B1,CA" {
+		t.Errorf("Expected zip K1A 0B1,CA to reach the geocoding API once, got %v", zips)
+	}
+	if requests := handler.Requests(); len(requests) != 3 || requests[0] != "/geo/1.0/zip" {
+		t.Errorf("Expected a zip lookup, then current weather and forecast, got %v", requests)
+	}
+}
+
+func TestClientReusesGeocoding(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+	defer server.Close()
+
+	opts := api.ProviderOptions{APIKey: "any", Endpoints: server.Endpoints(), CacheDir: t.TempDir()}
+
+	if _, err := api.NewClientWithOptions(opts).GetWeatherByCity(ctx, "New York", "NY", "US"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	before := len(server.Requests())
+
+	// A new client, as in a later run, finds the place in the cache and
+	// fetches by coordinates
+	data, err := api.NewClientWithOptions(opts).GetWeatherByCity(ctx, "new york", "ny", "us")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data.Location.Name != "New York" {
+		t.Errorf("Expected cached name New York, got %q", data.Location.Name)
+	}
+
+	requests := server.Requests()[before:]
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %v", requests)
+	}
+	for _, path := range requests {
+		if path != "/weather" && path != "/forecast" {
+			t.Errorf("Unexpected request %s", path)
+		}
+	}
+}
+
+func TestClientSearchLocations(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: server.Endpoints()})
+
+	tests := []struct {
+		city   string
+		states []string
+	}{
+		{"New York", []string{"New York"}},
+		{apitest.AmbiguousCity, []string{"Illinois", "Massachusetts", "Missouri"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.city, func(t *testing.T) {
+			locs, err := client.SearchLocations(ctx, tt.city, "", "")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(locs) != len(tt.states) {
+				t.Fatalf("Expected %d places, got %+v", len(tt.states), locs)
+			}
+			for i, state := range tt.states {
+				if locs[i].State != state {
+					t.Errorf("Expected place %d in %s, got %q", i, state, locs[i].State)
+				}
+			}
+		})
+	}
+
+	if _, err := client.SearchLocations(ctx, apitest.UnknownCity, "", ""); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown city, got %v", err)
+	}
+}
+
+func TestClientReverseGeocode(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: server.Endpoints()})
+
+	loc, err := client.ReverseGeocode(ctx, 40.7128, -74.006)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if loc.Name != "New York" || loc.State != "New York" || loc.Country != "US" {
+		t.Errorf("Expected New York, New York, US, got %+v", loc)
+	}
+	if loc.Latitude != 40.7128 || loc.Longitude != -74.006 {
+		t.Errorf("Expected the queried coordinates, got %f,%f", loc.Latitude, loc.Longitude)
+	}
+}
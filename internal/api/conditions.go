@@ -0,0 +1,110 @@
+package api
+
+import "strings"
+
+// condition pairs an OpenWeatherMap condition code with its description.
+// OpenWeatherMap codes are the condition model used throughout the app, so
+// other providers translate their own codes into them.
+type condition struct {
+	code        int
+	description string
+}
+
+// wmoConditions maps WMO weather interpretation codes (used by Open-Meteo)
+// to OpenWeatherMap condition codes
+var wmoConditions = map[int]condition{
+	0:  {800, "clear sky"},
+	1:  {801, "mainly clear"},
+	2:  {802, "partly cloudy"},
+	3:  {804, "overcast"},
+	45: {741, "fog"},
+	48: {741, "depositing rime fog"},
+	51: {300, "light drizzle"},
+	53: {301, "drizzle"},
+	55: {302, "dense drizzle"},
+	56: {511, "light freezing drizzle"},
+	57: {511, "dense freezing drizzle"},
+	61: {500, "light rain"},
+	63: {501, "moderate rain"},
+	65: {502, "heavy rain"},
+	66: {511, "light freezing rain"},
+	67: {511, "heavy freezing rain"},
+	71: {600, "light snow"},
+	73: {601, "snow"},
+	75: {602, "heavy snow"},
+	77: {600, "snow grains"},
+	80: {520, "light rain showers"},
+	81: {521, "rain showers"},
+	82: {522, "violent rain showers"},
+	85: {620, "light snow showers"},
+	86: {622, "heavy snow showers"},
+	95: {211, "thunderstorm"},
+	96: {201, "thunderstorm with light hail"},
+	99: {202, "thunderstorm with heavy hail"},
+}
+
+// wmoCondition returns the condition for a WMO weather code
+func wmoCondition(code int) condition {
+	if c, ok := wmoConditions[code]; ok {
+		return c
+	}
+	return condition{0, "unknown"}
+}
+
+// metSymbols maps MET Norway symbol codes, without their _day, _night or
+// _polartwilight suffix, to OpenWeatherMap condition codes
+var metSymbols = map[string]condition{
+	"clearsky":                     {800, "clear sky"},
+	"fair":                         {801, "fair"},
+	"partlycloudy":                 {802, "partly cloudy"},
+	"cloudy":                       {804, "cloudy"},
+	"fog":                          {741, "fog"},
+	"lightrainshowers":             {520, "light rain showers"},
+	"rainshowers":                  {521, "rain showers"},
+	"heavyrainshowers":             {522, "heavy rain showers"},
+	"lightrainshowersandthunder":   {200, "light rain showers and thunder"},
+	"rainshowersandthunder":        {201, "rain showers and thunder"},
+	"heavyrainshowersandthunder":   {202, "heavy rain showers and thunder"},
+	"lightsleetshowers":            {612, "light sleet showers"},
+	"sleetshowers":                 {613, "sleet showers"},
+	"heavysleetshowers":            {613, "heavy sleet showers"},
+	"lightssleetshowersandthunder": {200, "light sleet showers and thunder"},
+	"sleetshowersandthunder":       {201, "sleet showers and thunder"},
+	"heavysleetshowersandthunder":  {202, "heavy sleet showers and thunder"},
+	"lightsnowshowers":             {620, "light snow showers"},
+	"snowshowers":                  {621, "snow showers"},
+	"heavysnowshowers":             {622, "heavy snow showers"},
+	"lightssnowshowersandthunder":  {200, "light snow showers and thunder"},
+	"snowshowersandthunder":        {201, "snow showers and thunder"},
+	"heavysnowshowersandthunder":   {202, "heavy snow showers and thunder"},
+	"lightrain":                    {500, "light rain"},
+	"rain":                         {501, "rain"},
+	"heavyrain":                    {502, "heavy rain"},
+	"lightrainandthunder":          {200, "light rain and thunder"},
+	"rainandthunder":               {201, "rain and thunder"},
+	"heavyrainandthunder":          {202, "heavy rain and thunder"},
+	"lightsleet":                   {612, "light sleet"},
+	"sleet":                        {611, "sleet"},
+	"heavysleet":                   {613, "heavy sleet"},
+	"lightsleetandthunder":         {200, "light sleet and thunder"},
+	"sleetandthunder":              {201, "sleet and thunder"},
+	"heavysleetandthunder":         {202, "heavy sleet and thunder"},
+	"lightsnow":                    {600, "light snow"},
+	"snow":                         {601, "snow"},
+	"heavysnow":                    {602, "heavy snow"},
+	"lightsnowandthunder":          {200, "light snow and thunder"},
+	"snowandthunder":               {201, "snow and thunder"},
+	"heavysnowandthunder":          {202, "heavy snow and thunder"},
+}
+
+// metCondition returns the condition for a MET Norway symbol code such as
+// "partlycloudy_night"
+func metCondition(symbol string) condition {
+	for _, suffix := range []string{"_day", "_night", "_polartwilight"} {
+		symbol = strings.TrimSuffix(symbol, suffix)
+	}
+	if c, ok := metSymbols[symbol]; ok {
+		return c
+	}
+	return condition{0, "unknown"}
+}
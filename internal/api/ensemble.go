@@ -0,0 +1,404 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProviderEnsemble is the registry name of the ensemble provider
+const ProviderEnsemble = "ensemble"
+
+func init() {
+	RegisterProvider(ProviderEnsemble, func(opts ProviderOptions) (Provider, error) {
+		// An ensemble cannot contain itself
+		names := make([]string, 0, len(opts.Members))
+		for _, name := range opts.Members {
+			if !strings.EqualFold(name, ProviderEnsemble) {
+				names = append(names, name)
+			}
+		}
+		if len(names) < 2 {
+			return nil, fmt.Errorf("ensemble needs at least two providers, set them with 'weatherornot config set ensemble <a,b,...>'")
+		}
+
+		memberOpts := opts
+		memberOpts.Members = nil
+
+		members := make([]Provider, 0, len(names))
+		for _, name := range names {
+			p, err := NewProvider(name, memberOpts)
+			if err != nil {
+				return nil, fmt.Errorf("ensemble member %s: %w", name, err)
+			}
+			members = append(members, p)
+		}
+
+		return NewEnsemble(members...), nil
+	})
+}
+
+// Ensemble fetches the same location from several providers at once and
+// merges their answers into median values. Hourly forecasts keep the
+// range of temperatures across providers so disagreement stays visible.
+type Ensemble struct {
+	members []Provider
+}
+
+// NewEnsemble creates an ensemble of the given providers
+func NewEnsemble(members ...Provider) *Ensemble {
+	return &Ensemble{members: members}
+}
+
+// Name returns the registry name of the provider
+func (e *Ensemble) Name() string {
+	return ProviderEnsemble
+}
+
+// GetCurrent fetches current weather from every member
+func (e *Ensemble) GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return e.gather(e.members, func(p Provider) (*WeatherData, error) {
+		return p.GetCurrent(ctx, lat, lon)
+	})
+}
+
+// GetForecast fetches forecasts from every member
+func (e *Ensemble) GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return e.gather(e.members, func(p Provider) (*WeatherData, error) {
+		return p.GetForecast(ctx, lat, lon)
+	})
+}
+
+// Geocode converts city name to coordinates with the first member that
+// finds it
+func (e *Ensemble) Geocode(ctx context.Context, city, state, country string) (float64, float64, error) {
+	var lastErr error
+	for _, p := range e.members {
+		lat, lon, err := p.Geocode(ctx, city, state, country)
+		if err == nil {
+			return lat, lon, nil
+		}
+		lastErr = err
+	}
+	return 0, 0, lastErr
+}
+
+// SearchLocations returns the places matching a city name from the first
+// member that finds any
+func (e *Ensemble) SearchLocations(ctx context.Context, city, state, country string) ([]Location, error) {
+	var lastErr error
+	for _, p := range e.members {
+		locs, err := p.SearchLocations(ctx, city, state, country)
+		if err == nil {
+			return locs, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// ReverseGeocode returns the place at coordinates from the first member
+// that finds it
+func (e *Ensemble) ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	var lastErr error
+	for _, p := range e.members {
+		loc, err := p.ReverseGeocode(ctx, lat, lon)
+		if err == nil {
+			return loc, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// GetWeatherByZip fetches weather data by zip code
+func (e *Ensemble) GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error) {
+	return e.resolveThenGather(ctx, func(p Provider) (*WeatherData, error) {
+		return p.GetWeatherByZip(ctx, zip, countryCode)
+	})
+}
+
+// GetWeatherByCity fetches weather data by city name
+func (e *Ensemble) GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error) {
+	return e.resolveThenGather(ctx, func(p Provider) (*WeatherData, error) {
+		return p.GetWeatherByCity(ctx, city, state, country)
+	})
+}
+
+// GetWeatherByCoords fetches weather data by coordinates from every member
+func (e *Ensemble) GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return e.gather(e.members, func(p Provider) (*WeatherData, error) {
+		return p.GetWeatherByCoords(ctx, lat, lon)
+	})
+}
+
+// resolveThenGather lets the first member that succeeds resolve the
+// location, then fetches its coordinates from the remaining members so
+// every member forecasts exactly the same place
+func (e *Ensemble) resolveThenGather(ctx context.Context, lookup func(p Provider) (*WeatherData, error)) (*WeatherData, error) {
+	var lastErr error
+	for i, p := range e.members {
+		first, err := lookup(p)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		lat, lon := first.Location.Latitude, first.Location.Longitude
+		rest, restNames, _ := e.collect(e.members[i+1:], func(p Provider) (*WeatherData, error) {
+			return p.GetWeatherByCoords(ctx, lat, lon)
+		})
+
+		results := append([]*WeatherData{first}, rest...)
+		names := append([]string{p.Name()}, restNames...)
+		return mergeEnsemble(results, names), nil
+	}
+	return nil, lastErr
+}
+
+// gather calls fetch on each provider concurrently and merges the results
+func (e *Ensemble) gather(members []Provider, fetch func(p Provider) (*WeatherData, error)) (*WeatherData, error) {
+	results, names, err := e.collect(members, fetch)
+	if err != nil {
+		return nil, err
+	}
+	return mergeEnsemble(results, names), nil
+}
+
+// collect calls fetch on each provider concurrently. Members that fail are
+// left out; it is an error only if all of them fail.
+func (e *Ensemble) collect(members []Provider, fetch func(p Provider) (*WeatherData, error)) ([]*WeatherData, []string, error) {
+	results := make([]*WeatherData, len(members))
+	errs := make([]error, len(members))
+
+	var wg sync.WaitGroup
+	for i, p := range members {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			results[i], errs[i] = fetch(p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var ok []*WeatherData
+	var names []string
+	var failures multiError
+	for i, p := range members {
+		if errs[i] != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", p.Name(), errs[i]))
+			continue
+		}
+		ok = append(ok, results[i])
+		names = append(names, p.Name())
+	}
+
+	if len(ok) == 0 {
+		if len(failures) == 0 {
+			return nil, nil, fmt.Errorf("ensemble has no providers")
+		}
+		return nil, nil, fmt.Errorf("all ensemble providers failed: %w", failures)
+	}
+
+	return ok, names, nil
+}
+
+// mergeEnsemble merges member results into one WeatherData holding the
+// median of each value
+func mergeEnsemble(results []*WeatherData, names []string) *WeatherData {
+	first := results[0]
+	data := &WeatherData{
+		Location: first.Location,
+		Sources:  names,
+	}
+
+	// Current conditions
+	var cur struct{ temp, feels, humidity, pressure, wind, clouds, uv, visibility []float64 }
+	currents := make([]CurrentWeather, 0, len(results))
+	for _, r := range results {
+		if r.Current.Time.IsZero() {
+			continue
+		}
+		c := r.Current
+		currents = append(currents, c)
+		cur.temp = append(cur.temp, c.Temperature)
+		cur.feels = append(cur.feels, c.FeelsLike)
+		cur.humidity = append(cur.humidity, float64(c.Humidity))
+		cur.pressure = appendNonZero(cur.pressure, float64(c.Pressure))
+		cur.wind = append(cur.wind, c.WindSpeed)
+		cur.clouds = append(cur.clouds, float64(c.CloudCover))
+		cur.uv = appendNonZero(cur.uv, c.UVIndex)
+		cur.visibility = appendNonZero(cur.visibility, float64(c.Visibility))
+	}
+	if len(currents) > 0 {
+		c := currents[0]
+		c.Temperature = median(cur.temp)
+		c.FeelsLike = median(cur.feels)
+		c.Humidity = int(median(cur.humidity))
+		c.Pressure = int(median(cur.pressure))
+		c.WindSpeed = median(cur.wind)
+		c.CloudCover = int(median(cur.clouds))
+		c.UVIndex = median(cur.uv)
+		c.Visibility = int(median(cur.visibility))
+
+		codes := make([]int, len(currents))
+		for i, cw := range currents {
+			codes[i] = cw.ConditionCode
+		}
+		if idx := modeIndex(codes); idx >= 0 {
+			c.Condition = currents[idx].Condition
+			c.ConditionCode = currents[idx].ConditionCode
+			c.Icon = currents[idx].Icon
+		}
+		data.Current = c
+	}
+
+	// Hourly forecasts, aligned on the hour
+	hours := make(map[int64][]HourlyForecast)
+	for _, r := range results {
+		for _, h := range r.Hourly {
+			key := h.Time.Truncate(time.Hour).Unix()
+			hours[key] = append(hours[key], h)
+		}
+	}
+	for key, group := range hours {
+		var temps, feels, humidity, wind, precip []float64
+		codes := make([]int, len(group))
+		for i, h := range group {
+			temps = append(temps, h.Temperature)
+			feels = append(feels, h.FeelsLike)
+			humidity = append(humidity, float64(h.Humidity))
+			wind = append(wind, h.WindSpeed)
+			precip = append(precip, float64(h.PrecipChance))
+			codes[i] = h.ConditionCode
+		}
+
+		h := group[modeIndex(codes)]
+		h.Time = time.Unix(key, 0)
+		h.Temperature = median(temps)
+		h.FeelsLike = median(feels)
+		h.Humidity = int(median(humidity))
+		h.WindSpeed = median(wind)
+		h.PrecipChance = int(median(precip))
+		h.TempSpread = spread(temps)
+		h.Sources = len(group)
+		data.Hourly = append(data.Hourly, h)
+	}
+	sort.Slice(data.Hourly, func(i, j int) bool {
+		return data.Hourly[i].Time.Before(data.Hourly[j].Time)
+	})
+
+	// Daily forecasts, aligned on the local date
+	days := make(map[string][]DailyForecast)
+	var dayKeys []string
+	for _, r := range results {
+		for _, d := range r.Daily {
+			key := d.Date.Local().Format("2006-01-02")
+			if _, exists := days[key]; !exists {
+				dayKeys = append(dayKeys, key)
+			}
+			days[key] = append(days[key], d)
+		}
+	}
+	sort.Strings(dayKeys)
+	for _, key := range dayKeys {
+		group := days[key]
+		var maxTemps, minTemps, humidity, wind, precip, uv []float64
+		codes := make([]int, len(group))
+		for i, d := range group {
+			maxTemps = append(maxTemps, d.TempMax)
+			minTemps = append(minTemps, d.TempMin)
+			humidity = append(humidity, float64(d.Humidity))
+			wind = append(wind, d.WindSpeed)
+			precip = append(precip, float64(d.PrecipChance))
+			uv = appendNonZero(uv, d.UVIndex)
+			codes[i] = d.ConditionCode
+		}
+
+		d := group[modeIndex(codes)]
+		d.TempMax = median(maxTemps)
+		d.TempMin = median(minTemps)
+		d.Humidity = int(median(humidity))
+		d.WindSpeed = median(wind)
+		d.PrecipChance = int(median(precip))
+		d.UVIndex = median(uv)
+		data.Daily = append(data.Daily, d)
+	}
+
+	// Minutely data and alerts come from whichever members provide them
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if len(data.Minutely) == 0 {
+			data.Minutely = r.Minutely
+		}
+		for _, a := range r.Alerts {
+			key := a.Event + a.Start.String()
+			if !seen[key] {
+				seen[key] = true
+				data.Alerts = append(data.Alerts, a)
+			}
+		}
+	}
+
+	return data
+}
+
+// median returns the median of values, or 0 for none
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// spread returns the smallest and largest of values
+func spread(values []float64) Spread {
+	if len(values) == 0 {
+		return Spread{}
+	}
+
+	s := Spread{Min: values[0], Max: values[0]}
+	for _, v := range values[1:] {
+		if v < s.Min {
+			s.Min = v
+		}
+		if v > s.Max {
+			s.Max = v
+		}
+	}
+	return s
+}
+
+// appendNonZero appends v unless it is zero, for values that providers
+// leave at zero when they don't report them
+func appendNonZero(values []float64, v float64) []float64 {
+	if v == 0 {
+		return values
+	}
+	return append(values, v)
+}
+
+// modeIndex returns the index of an element holding the most common value,
+// or -1 for none
+func modeIndex(values []int) int {
+	counts := make(map[int]int)
+	best := -1
+	for i, v := range values {
+		counts[v]++
+		if best < 0 || counts[v] > counts[values[best]] {
+			best = i
+		}
+	}
+	return best
+}
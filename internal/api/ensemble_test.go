@@ -0,0 +1,97 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeEnsemble(t *testing.T) {
+	hour := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	results := []*WeatherData{
+		{
+			Location: Location{Name: "Denver"},
+			Current:  CurrentWeather{Temperature: 70, ConditionCode: 800, Time: hour},
+			Hourly:   []HourlyForecast{{Time: hour, Temperature: 70}},
+		},
+		{
+			Current: CurrentWeather{Temperature: 78, ConditionCode: 801, Time: hour},
+			Hourly:  []HourlyForecast{{Time: hour.Add(20 * time.Minute), Temperature: 78}},
+		},
+		{
+			Current: CurrentWeather{Temperature: 72, ConditionCode: 800, Time: hour},
+			Hourly: []HourlyForecast{
+				{Time: hour, Temperature: 72},
+				{Time: hour.Add(time.Hour), Temperature: 75},
+			},
+		},
+	}
+
+	data := mergeEnsemble(results, []string{"a", "b", "c"})
+
+	if data.Location.Name != "Denver" {
+		t.Errorf("Expected location from first member, got %q", data.Location.Name)
+	}
+	if data.Current.Temperature != 72 {
+		t.Errorf("Expected median temperature 72, got %.1f", data.Current.Temperature)
+	}
+	if data.Current.ConditionCode != 800 {
+		t.Errorf("Expected most common condition 800, got %d", data.Current.ConditionCode)
+	}
+	if len(data.Hourly) != 2 {
+		t.Fatalf("Expected 2 hourly entries, got %d", len(data.Hourly))
+	}
+
+	first := data.Hourly[0]
+	if first.Sources != 3 {
+		t.Errorf("Expected 3 sources for first hour, got %d", first.Sources)
+	}
+	if first.TempSpread.Min != 70 || first.TempSpread.Max != 78 {
+		t.Errorf("Expected spread 70-78, got %.1f-%.1f", first.TempSpread.Min, first.TempSpread.Max)
+	}
+	if data.Hourly[1].Sources != 1 || data.Hourly[1].Temperature != 75 {
+		t.Errorf("Expected single-source second hour at 75, got %+v", data.Hourly[1])
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		expect float64
+	}{
+		{nil, 0},
+		{[]float64{5}, 5},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+
+	for _, tt := range tests {
+		if got := median(tt.values); got != tt.expect {
+			t.Errorf("median(%v) = %v, expected %v", tt.values, got, tt.expect)
+		}
+	}
+}
+
+func TestEnsembleMembers(t *testing.T) {
+	tests := []struct {
+		members []string
+		wantErr bool
+	}{
+		{[]string{"openmeteo", "metno"}, false},
+		{[]string{"openmeteo", "ensemble", "metno"}, false},
+		// Naming the ensemble itself does not make up the two providers
+		{[]string{"openmeteo", "ensemble"}, true},
+		{[]string{"ensemble", "Ensemble"}, true},
+	}
+
+	for _, tt := range tests {
+		p, err := NewProvider(ProviderEnsemble, ProviderOptions{Members: tt.members, CacheDir: t.TempDir()})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewProvider(ensemble, %v) error = %v, wantErr %v", tt.members, err, tt.wantErr)
+			continue
+		}
+		if err == nil && len(p.(*Ensemble).members) != 2 {
+			t.Errorf("Expected 2 members for %v, got %d", tt.members, len(p.(*Ensemble).members))
+		}
+	}
+}
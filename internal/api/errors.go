@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors that API errors wrap according to their status code, for use
+// with errors.Is
+var (
+	ErrUnauthorized = errors.New("invalid or missing API key")
+	ErrNotFound     = errors.New("location not found")
+	ErrRateLimited  = errors.New("rate limit exceeded")
+	ErrServer       = errors.New("weather service unavailable")
+)
+
+// APIError is an unsuccessful response from a weather API
+type APIError struct {
+	StatusCode int
+	Message    string
+
+	// RetryAfter is how long the API asked clients to wait before trying
+	// again, or 0 if it did not say
+	RetryAfter time.Duration
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API error (status %d)", e.StatusCode)
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the sentinel error matching the status code, if any
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+// statusError builds the error for an unexpected response status
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    errorMessage(body),
+		RetryAfter: retryAfter(resp),
+	}
+}
+
+// errorMessage extracts the message from an error response body. Weather
+// APIs put it in different fields; anything else is returned trimmed.
+func errorMessage(body []byte) string {
+	var fields struct {
+		Message string `json:"message"`
+		Detail  string `json:"detail"`
+		Reason  string `json:"reason"`
+	}
+	if json.Unmarshal(body, &fields) == nil {
+		for _, msg := range []string{fields.Message, fields.Detail, fields.Reason} {
+			if msg != "" {
+				return msg
+			}
+		}
+	}
+
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > 200 {
+		msg = msg[:200] + "..."
+	}
+	return msg
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as a date
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// IsUnavailable reports whether err means the weather service could not
+// be reached or could not answer in time, as opposed to a problem with the
+// request itself or a cancelled request
+func IsUnavailable(err error) bool {
+	return err != nil &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, ErrUnauthorized) &&
+		!errors.Is(err, ErrNotFound) &&
+		!errors.Is(err, ErrNoCachedData)
+}
+
+// multiError reports several errors on one line while keeping each of
+// them visible to errors.Is and errors.As
+type multiError []error
+
+// Error implements error
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the joined errors
+func (m multiError) Unwrap() []error {
+	return m
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStatusErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+		message  string
+	}{
+		{"unauthorized", 401, `{"cod":401,"message":"Invalid API key."}`, ErrUnauthorized, "Invalid API key."},
+		{"not found", 404, `{"cod":"404","message":"city not found"}`, ErrNotFound, "city not found"},
+		{"nws detail", 404, `{"title":"Not Found","detail":"Data Unavailable For Requested Point"}`, ErrNotFound, "Data Unavailable For Requested Point"},
+		{"rate limited", 429, `{"cod":429,"message":"Your account is temporary blocked"}`, ErrRateLimited, "Your account is temporary blocked"},
+		{"server error", 503, "Service Unavailable", ErrServer, "Service Unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			f := newFetcher("")
+			f.retries = 0
+
+			var v struct{}
+			err := f.getJSON(context.Background(), server.URL, &v)
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("Expected %v, got %v", tt.sentinel, err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *APIError, got %T", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.message {
+				t.Errorf("Expected %d %q, got %d %q", tt.status, tt.message, apiErr.StatusCode, apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestFetcherRetries(t *testing.T) {
+	defer func(d time.Duration) { retryBaseDelay = d }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	var v struct{ OK bool }
+	if err := newFetcher("").getJSON(context.Background(), server.URL, &v); err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if calls != 3 || !v.OK {
+		t.Errorf("Expected 3 calls and a decoded body, got %d calls", calls)
+	}
+}
+
+func TestFetcherGivesUpOnLongRetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var v struct{}
+	err := newFetcher("").getJSON(context.Background(), server.URL, &v)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Hour {
+		t.Fatalf("Expected rate limit error with Retry-After, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected no retry, got %d calls", calls)
+	}
+}
+
+func TestFetcherStalledServer(t *testing.T) {
+	defer func(d time.Duration) { responseHeaderTimeout = d }(responseHeaderTimeout)
+	defer func(d time.Duration) { retryBaseDelay = d }(retryBaseDelay)
+	responseHeaderTimeout = 50 * time.Millisecond
+	retryBaseDelay = time.Millisecond
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// No deadline on the context; the fetcher must still give up
+	start := time.Now()
+	var v struct{}
+	if err := newFetcher("").getJSON(context.Background(), server.URL, &v); err == nil {
+		t.Fatal("Expected an error from a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected to give up quickly, took %v", elapsed)
+	}
+}
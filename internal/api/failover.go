@@ -0,0 +1,274 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// unhealthyAfter is the number of consecutive failures after which a
+	// provider is tried last
+	unhealthyAfter = 3
+
+	// unhealthyCooldown is how long an unhealthy provider stays demoted
+	unhealthyCooldown = 5 * time.Minute
+)
+
+// Failover tries an ordered chain of providers and returns the first
+// successful answer. Providers that keep failing are tried last until
+// their cooldown has passed.
+type Failover struct {
+	providers []Provider
+	health    *Health
+
+	// OnFailure, when set, is called for every provider that fails
+	// before the chain moves on to the next one
+	OnFailure func(provider string, err error)
+}
+
+// NewFailover creates a failover chain of providers, primary first.
+// A nil health tracker keeps health in memory only.
+func NewFailover(health *Health, providers ...Provider) *Failover {
+	if health == nil {
+		health = NewHealth()
+	}
+	return &Failover{
+		providers: providers,
+		health:    health,
+	}
+}
+
+// Name returns the names of the chained providers
+func (f *Failover) Name() string {
+	names := make([]string, len(f.providers))
+	for i, p := range f.providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// GetCurrent fetches current weather from the first provider that succeeds
+func (f *Failover) GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return f.try(ctx, func(p Provider) (*WeatherData, error) {
+		return p.GetCurrent(ctx, lat, lon)
+	})
+}
+
+// GetForecast fetches forecasts from the first provider that succeeds
+func (f *Failover) GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return f.try(ctx, func(p Provider) (*WeatherData, error) {
+		return p.GetForecast(ctx, lat, lon)
+	})
+}
+
+// Geocode converts city name to coordinates with the first provider that
+// succeeds
+func (f *Failover) Geocode(ctx context.Context, city, state, country string) (float64, float64, error) {
+	var lat, lon float64
+	_, err := f.try(ctx, func(p Provider) (*WeatherData, error) {
+		var err error
+		lat, lon, err = p.Geocode(ctx, city, state, country)
+		return &WeatherData{}, err
+	})
+	return lat, lon, err
+}
+
+// SearchLocations returns the places matching a city name from the first
+// provider that succeeds
+func (f *Failover) SearchLocations(ctx context.Context, city, state, country string) ([]Location, error) {
+	var locs []Location
+	_, err := f.try(ctx, func(p Provider) (*WeatherData, error) {
+		var err error
+		locs, err = p.SearchLocations(ctx, city, state, country)
+		return &WeatherData{}, err
+	})
+	return locs, err
+}
+
+// ReverseGeocode returns the place at coordinates from the first provider
+// that succeeds
+func (f *Failover) ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	var loc *Location
+	_, err := f.try(ctx, func(p Provider) (*WeatherData, error) {
+		var err error
+		loc, err = p.ReverseGeocode(ctx, lat, lon)
+		return &WeatherData{}, err
+	})
+	return loc, err
+}
+
+// GetWeatherByZip fetches weather data by zip code
+func (f *Failover) GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error) {
+	return f.try(ctx, func(p Provider) (*WeatherData, error) {
+		return p.GetWeatherByZip(ctx, zip, countryCode)
+	})
+}
+
+// GetWeatherByCity fetches weather data by city name
+func (f *Failover) GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error) {
+	return f.try(ctx, func(p Provider) (*WeatherData, error) {
+		return p.GetWeatherByCity(ctx, city, state, country)
+	})
+}
+
+// GetWeatherByCoords fetches weather data by coordinates
+func (f *Failover) GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return f.try(ctx, func(p Provider) (*WeatherData, error) {
+		return p.GetWeatherByCoords(ctx, lat, lon)
+	})
+}
+
+// try calls fetch on each provider in turn until one succeeds or ctx is
+// done, and records the provider that served the data in
+// WeatherData.Source
+func (f *Failover) try(ctx context.Context, fetch func(p Provider) (*WeatherData, error)) (*WeatherData, error) {
+	var failures multiError
+
+	for _, p := range f.ordered() {
+		data, err := fetch(p)
+		if err == nil {
+			f.health.recordSuccess(p.Name())
+			if data.Source == "" {
+				data.Source = p.Name()
+			}
+			return data, nil
+		}
+
+		// A cancelled request says nothing about the provider
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		f.health.recordFailure(p.Name(), err)
+		if f.OnFailure != nil {
+			f.OnFailure(p.Name(), err)
+		}
+		failures = append(failures, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+
+	if len(failures) == 0 {
+		return nil, fmt.Errorf("no providers configured")
+	}
+	return nil, fmt.Errorf("all providers failed: %w", failures)
+}
+
+// ordered returns healthy providers in configured order followed by the
+// unhealthy ones, so a dead primary does not slow down every request
+func (f *Failover) ordered() []Provider {
+	healthy := make([]Provider, 0, len(f.providers))
+	var unhealthy []Provider
+	for _, p := range f.providers {
+		if f.health.IsHealthy(p.Name()) {
+			healthy = append(healthy, p)
+		} else {
+			unhealthy = append(unhealthy, p)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// ProviderHealth is the recent track record of a provider
+type ProviderHealth struct {
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastFailure         time.Time `json:"last_failure,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+}
+
+// Health tracks provider failures, optionally persisted to a file so that
+// separate runs of the CLI share it
+type Health struct {
+	mu        sync.Mutex
+	path      string
+	providers map[string]*ProviderHealth
+}
+
+// NewHealth creates an in-memory health tracker
+func NewHealth() *Health {
+	return &Health{providers: make(map[string]*ProviderHealth)}
+}
+
+// LoadHealth loads a health tracker from path. A missing or unreadable
+// file starts with a clean record.
+func LoadHealth(path string) *Health {
+	h := NewHealth()
+	h.path = path
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return h
+	}
+	if err := json.Unmarshal(raw, &h.providers); err != nil || h.providers == nil {
+		h.providers = make(map[string]*ProviderHealth)
+	}
+	return h
+}
+
+// Save writes the health record to the file it was loaded from
+func (h *Health) Save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	h.mu.Lock()
+	raw, err := json.MarshalIndent(h.providers, "", "  ")
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, raw, 0644)
+}
+
+// Status returns a copy of the health record of a provider
+func (h *Health) Status(name string) ProviderHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if ph, ok := h.providers[name]; ok {
+		return *ph
+	}
+	return ProviderHealth{}
+}
+
+// IsHealthy reports whether a provider should be tried in its configured
+// position
+func (h *Health) IsHealthy(name string) bool {
+	status := h.Status(name)
+	if status.ConsecutiveFailures < unhealthyAfter {
+		return true
+	}
+	return time.Since(status.LastFailure) > unhealthyCooldown
+}
+
+// recordSuccess resets the failure count of a provider
+func (h *Health) recordSuccess(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.providers[name] = &ProviderHealth{LastSuccess: time.Now()}
+}
+
+// recordFailure counts a failure of a provider
+func (h *Health) recordFailure(name string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ph, ok := h.providers[name]
+	if !ok {
+		ph = &ProviderHealth{}
+		h.providers[name] = ph
+	}
+	ph.ConsecutiveFailures++
+	ph.LastFailure = time.Now()
+	ph.LastError = err.Error()
+}
@@ -0,0 +1,166 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/apitest"
+	"github.com/james-see/weatherornot/internal/cache"
+)
+
+// named gives a provider its own name, so several clients of the same
+// API can stand for different providers in a chain
+type named struct {
+	api.Provider
+	name string
+}
+
+func (n named) Name() string { return n.name }
+
+// failoverProviders returns a provider answering from a fake server and
+// one whose API key the fake server rejects, with the servers behind them
+func failoverProviders(t *testing.T) (good, bad api.Provider, goodServer, badServer *apitest.Server) {
+	t.Helper()
+	goodServer = apitest.NewServer("")
+	badServer = apitest.NewServer("right-key")
+	t.Cleanup(goodServer.Close)
+	t.Cleanup(badServer.Close)
+
+	good = named{api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: goodServer.Endpoints()}), "good"}
+	bad = named{api.NewClientWithOptions(api.ProviderOptions{APIKey: "wrong-key", Endpoints: badServer.Endpoints()}), "bad"}
+	return good, bad, goodServer, badServer
+}
+
+func TestFailoverOrder(t *testing.T) {
+	ctx := context.Background()
+	good, bad, _, _ := failoverProviders(t)
+
+	tests := []struct {
+		name       string
+		chain      []api.Provider
+		wantSource string
+		wantFailed []string
+		wantErr    bool
+	}{
+		{"healthy primary", []api.Provider{good, bad}, "good", nil, false},
+		{"failing primary", []api.Provider{bad, good}, "good", []string{"bad"}, false},
+		{"all failing", []api.Provider{bad}, "", []string{"bad"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failed []string
+			failover := api.NewFailover(nil, tt.chain...)
+			failover.OnFailure = func(provider string, err error) {
+				failed = append(failed, provider)
+			}
+
+			data, err := failover.GetWeatherByCoords(ctx, 40.7128, -74.006)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && data.Source != tt.wantSource {
+				t.Errorf("Expected data from %s, got %q", tt.wantSource, data.Source)
+			}
+			if len(failed) != len(tt.wantFailed) || (len(failed) > 0 && failed[0] != tt.wantFailed[0]) {
+				t.Errorf("Expected failures %v, got %v", tt.wantFailed, failed)
+			}
+		})
+	}
+}
+
+func TestFailoverDemotesFailingProvider(t *testing.T) {
+	ctx := context.Background()
+	good, bad, _, badServer := failoverProviders(t)
+	path := filepath.Join(t.TempDir(), "health.json")
+
+	health := api.LoadHealth(path)
+	failover := api.NewFailover(health, bad, good)
+
+	// Three failures in a row demote the primary
+	for i := 0; i < 3; i++ {
+		if _, err := failover.GetWeatherByCoords(ctx, 40.7128, -74.006); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if health.IsHealthy("bad") || !health.IsHealthy("good") {
+		t.Fatalf("Expected bad to be demoted and good healthy, got %+v and %+v", health.Status("bad"), health.Status("good"))
+	}
+
+	before := len(badServer.Requests())
+	data, err := failover.GetWeatherByCoords(ctx, 40.7128, -74.006)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := len(badServer.Requests()); got != before || data.Source != "good" {
+		t.Errorf("Expected the demoted provider to be skipped, got %d requests and data from %q", got-before, data.Source)
+	}
+
+	// The record survives to the next run
+	if err := health.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded := api.LoadHealth(path)
+	if status := loaded.Status("bad"); status.ConsecutiveFailures != 3 || status.LastError == "" {
+		t.Errorf("Expected 3 saved failures with an error, got %+v", status)
+	}
+	if loaded.IsHealthy("bad") {
+		t.Error("Expected bad to stay demoted after loading")
+	}
+}
+
+func TestFailoverRetriesAfterCooldown(t *testing.T) {
+	ctx := context.Background()
+	good, bad, _, badServer := failoverProviders(t)
+
+	// The primary was demoted longer ago than the cooldown
+	path := filepath.Join(t.TempDir(), "health.json")
+	raw, _ := json.Marshal(map[string]api.ProviderHealth{
+		"bad": {ConsecutiveFailures: 3, LastFailure: time.Now().Add(-10 * time.Minute)},
+	})
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	health := api.LoadHealth(path)
+	if !health.IsHealthy("bad") {
+		t.Fatal("Expected the cooldown to have passed")
+	}
+	if _, err := api.NewFailover(health, bad, good).GetWeatherByCoords(ctx, 40.7128, -74.006); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(badServer.Requests()) == 0 {
+		t.Error("Expected the primary to be tried first again")
+	}
+	if status := health.Status("bad"); status.ConsecutiveFailures != 4 {
+		t.Errorf("Expected a fourth failure, got %+v", status)
+	}
+}
+
+func TestCachedDoesNotReplaySource(t *testing.T) {
+	ctx := context.Background()
+	good, bad, _, _ := failoverProviders(t)
+	cached := api.NewCached(api.NewFailover(nil, bad, good), cache.New(t.TempDir()), api.CacheTTL{}, "imperial")
+
+	fresh, err := cached.GetWeatherByCity(ctx, "New York", "NY", "US")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fresh.Source != "good" {
+		t.Errorf("Expected fresh data from good, got %q", fresh.Source)
+	}
+
+	// No provider answered the cached data, so none is named
+	replayed, err := cached.GetWeatherByCity(ctx, "New York", "NY", "US")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if replayed.Source != "" {
+		t.Errorf("Expected cached data without a source, got %q", replayed.Source)
+	}
+}
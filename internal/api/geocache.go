@@ -0,0 +1,65 @@
+package api
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/cache"
+)
+
+// geocodeCache remembers resolved locations in memory and, when a cache
+// directory is configured, on disk across runs
+type geocodeCache struct {
+	mu     sync.Mutex
+	places map[string]Location
+	store  *cache.Cache
+	ttl    time.Duration
+
+	// refresh skips results stored by earlier runs but still stores new ones
+	refresh bool
+}
+
+// newGeocodeCache creates a geocoding cache for a provider. An empty
+// cacheDir keeps results in memory only; a zero ttl uses the default
+// geocoding cache lifetime.
+func newGeocodeCache(provider, cacheDir string, ttl time.Duration, refresh bool) *geocodeCache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL.Geocode
+	}
+	g := &geocodeCache{places: make(map[string]Location), ttl: ttl, refresh: refresh}
+	if cacheDir != "" {
+		g.store = cache.New(filepath.Join(cacheDir, "geocode", provider))
+	}
+	return g
+}
+
+// get returns the location cached for place
+func (g *geocodeCache) get(place string) (*Location, bool) {
+	g.mu.Lock()
+	loc, ok := g.places[place]
+	g.mu.Unlock()
+	if ok {
+		return &loc, true
+	}
+
+	if g.store == nil || g.refresh || !g.store.Get(place, g.ttl, &loc) {
+		return nil, false
+	}
+
+	g.mu.Lock()
+	g.places[place] = loc
+	g.mu.Unlock()
+	return &loc, true
+}
+
+// put caches the location of place
+func (g *geocodeCache) put(place string, loc Location) {
+	g.mu.Lock()
+	g.places[place] = loc
+	g.mu.Unlock()
+
+	if g.store != nil {
+		g.store.Put(place, loc)
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGeocodeCache(t *testing.T) {
+	dir := t.TempDir()
+	newGeocodeCache(ProviderOpenWeatherMap, dir, 0, false).put("10001,US", Location{Name: "New York"})
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		refresh bool
+		want    bool
+	}{
+		{"default ttl", 0, false, true},
+		{"expired", time.Nanosecond, false, false},
+		{"refresh", 0, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := newGeocodeCache(ProviderOpenWeatherMap, dir, tt.ttl, tt.refresh).get("10001,US")
+			if ok != tt.want {
+				t.Errorf("Expected cached place %v, got %v", tt.want, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	openMeteoGeocodingURL = "https://geocoding-api.open-meteo.com/v1"
+	nominatimURL          = "https://nominatim.openstreetmap.org"
+)
+
+// openMeteoGeocoder resolves names and postal codes with the keyless
+// Open-Meteo geocoding API, and coordinates with Nominatim, since
+// Open-Meteo has no reverse geocoding. Providers without their own
+// geocoding endpoint use it as well.
+type openMeteoGeocoder struct {
+	http       *fetcher
+	baseURL    string
+	reverseURL string
+}
+
+// newOpenMeteoGeocoder creates a geocoder using the given fetcher and the
+// geocoding endpoints configured in opts
+func newOpenMeteoGeocoder(f *fetcher, opts ProviderOptions) *openMeteoGeocoder {
+	return &openMeteoGeocoder{
+		http:       f,
+		baseURL:    opts.endpoint(EndpointOpenMeteoGeo, openMeteoGeocodingURL),
+		reverseURL: opts.endpoint(EndpointNominatim, nominatimURL),
+	}
+}
+
+// search queries the geocoding API for name, optionally limited to a country
+func (g *openMeteoGeocoder) search(ctx context.Context, name, countryCode string) (*OpenMeteoGeocodingResponse, error) {
+	params := url.Values{}
+	params.Set("name", name)
+	params.Set("count", "10")
+	params.Set("language", "en")
+	params.Set("format", "json")
+	if countryCode != "" {
+		params.Set("countryCode", strings.ToUpper(countryCode))
+	}
+
+	var geoResp OpenMeteoGeocodingResponse
+	if err := g.http.getJSON(ctx, g.baseURL+"/search?"+params.Encode(), &geoResp); err != nil {
+		return nil, fmt.Errorf("error geocoding location: %w", err)
+	}
+
+	if len(geoResp.Results) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return &geoResp, nil
+}
+
+// geocodeCity resolves a city, preferring results in the given state
+func (g *openMeteoGeocoder) geocodeCity(ctx context.Context, city, state, country string) (*Location, error) {
+	locs, err := g.searchCities(ctx, city, state, country)
+	if err != nil {
+		return nil, err
+	}
+	return &locs[0], nil
+}
+
+// searchCities returns the places matching a city name. When some of them
+// are in the given state, only those are returned.
+func (g *openMeteoGeocoder) searchCities(ctx context.Context, city, state, country string) ([]Location, error) {
+	geoResp, err := g.search(ctx, city, country)
+	if err != nil {
+		return nil, err
+	}
+
+	var all, inState []Location
+	for _, r := range geoResp.Results {
+		loc := Location{
+			Name:      r.Name,
+			State:     r.Admin1,
+			Country:   r.CountryCode,
+			Latitude:  r.Latitude,
+			Longitude: r.Longitude,
+			Timezone:  r.Timezone,
+		}
+		all = append(all, loc)
+
+		// "City,ST" is ambiguous between a state and a country code, so
+		// accept either an admin1 or a country match for the second part
+		if state != "" && (matchesState(r.Admin1, state) || strings.EqualFold(r.CountryCode, state)) {
+			inState = append(inState, loc)
+		}
+	}
+
+	if len(inState) > 0 {
+		return uniqueLocations(inState), nil
+	}
+	return uniqueLocations(all), nil
+}
+
+// geocodeZip resolves a postal code within a country
+func (g *openMeteoGeocoder) geocodeZip(ctx context.Context, zip, countryCode string) (*Location, error) {
+	geoResp, err := g.search(ctx, zip, countryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	r := geoResp.Results[0]
+	return &Location{
+		Name:      r.Name,
+		Country:   r.CountryCode,
+		Latitude:  r.Latitude,
+		Longitude: r.Longitude,
+		Timezone:  r.Timezone,
+	}, nil
+}
+
+// reverseGeocode returns the place at coordinates using Nominatim
+func (g *openMeteoGeocoder) reverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	params := url.Values{}
+	params.Set("lat", fmt.Sprintf("%f", lat))
+	params.Set("lon", fmt.Sprintf("%f", lon))
+	params.Set("format", "jsonv2")
+	params.Set("zoom", "10")
+	params.Set("accept-language", "en")
+
+	var resp NominatimReverseResponse
+	if err := g.http.getJSON(ctx, g.reverseURL+"/reverse?"+params.Encode(), &resp); err != nil {
+		return nil, fmt.Errorf("error reverse geocoding location: %w", err)
+	}
+
+	// Nominatim reports places it cannot resolve, like open sea, in the body
+	if resp.Error != "" {
+		return nil, ErrNotFound
+	}
+
+	a := resp.Address
+	name := firstNonEmpty(a.City, a.Town, a.Village, a.Hamlet, a.Municipality, resp.Name, a.County)
+	if name == "" {
+		return nil, ErrNotFound
+	}
+
+	return &Location{
+		Name:      name,
+		State:     a.State,
+		Country:   strings.ToUpper(a.CountryCode),
+		Latitude:  lat,
+		Longitude: lon,
+	}, nil
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// uniqueLocations drops repeated places, which geocoding APIs return for
+// alternate names of the same city
+func uniqueLocations(locs []Location) []Location {
+	seen := make(map[string]bool)
+	unique := make([]Location, 0, len(locs))
+	for _, loc := range locs {
+		key := normalizePlace(loc.Name, loc.State, loc.Country)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, loc)
+	}
+	return unique
+}
+
+// matchesState reports whether a region name matches a state name or
+// US postal abbreviation
+func matchesState(region, state string) bool {
+	if strings.EqualFold(region, state) {
+		return true
+	}
+	if name, ok := usStates[strings.ToUpper(state)]; ok {
+		return strings.EqualFold(region, name)
+	}
+	return false
+}
+
+// usStates maps US postal abbreviations to state names
+var usStates = map[string]string{
+	"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas",
+	"CA": "California", "CO": "Colorado", "CT": "Connecticut", "DE": "Delaware",
+	"DC": "District of Columbia", "FL": "Florida", "GA": "Georgia", "HI": "Hawaii",
+	"ID": "Idaho", "IL": "Illinois", "IN": "Indiana", "IA": "Iowa",
+	"KS": "Kansas", "KY": "Kentucky", "LA": "Louisiana", "ME": "Maine",
+	"MD": "Maryland", "MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota",
+	"MS": "Mississippi", "MO": "Missouri", "MT": "Montana", "NE": "Nebraska",
+	"NV": "Nevada", "NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico",
+	"NY": "New York", "NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio",
+	"OK": "Oklahoma", "OR": "Oregon", "PA": "Pennsylvania", "RI": "Rhode Island",
+	"SC": "South Carolina", "SD": "South Dakota", "TN": "Tennessee", "TX": "Texas",
+	"UT": "Utah", "VT": "Vermont", "VA": "Virginia", "WA": "Washington",
+	"WV": "West Virginia", "WI": "Wisconsin", "WY": "Wyoming", "PR": "Puerto Rico",
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// gpsdFixWait is the longest a GPS receiver is given to report a fix
+const gpsdFixWait = 15 * time.Second
+
+// GPSDLocator reads the position from a gpsd daemon over its JSON protocol
+type GPSDLocator struct {
+	address string
+}
+
+// NewGPSDLocator creates a locator talking to gpsd at address, or
+// DefaultGPSDAddress if address is empty
+func NewGPSDLocator(address string) *GPSDLocator {
+	if strings.TrimSpace(address) == "" {
+		address = DefaultGPSDAddress
+	}
+	return &GPSDLocator{address: strings.TrimSpace(address)}
+}
+
+// gpsdReport is one line of gpsd output. Only the fields of the report
+// classes weatherornot reads are decoded.
+type gpsdReport struct {
+	Class string `json:"class"`
+
+	// TPV (time-position-velocity): mode is 0 or 1 without a fix, 2 for
+	// a 2D fix and 3 for a 3D fix
+	Mode int      `json:"mode"`
+	Lat  *float64 `json:"lat"`
+	Lon  *float64 `json:"lon"`
+
+	// DEVICES
+	Devices []json.RawMessage `json:"devices"`
+
+	// ERROR
+	Message string `json:"message"`
+}
+
+// Locate asks gpsd to stream reports and returns the first position fix
+func (l *GPSDLocator) Locate(ctx context.Context) (*Position, error) {
+	ctx, cancel := context.WithTimeout(ctx, gpsdFixWait)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", l.address)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to gpsd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Unblock the read below if the context is cancelled early
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if _, err := fmt.Fprint(conn, `?WATCH={"enable":true,"json":true};`+"\n"); err != nil {
+		return nil, fmt.Errorf("could not talk to gpsd: %w", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var r gpsdReport
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		switch r.Class {
+		case "ERROR":
+			return nil, fmt.Errorf("gpsd error: %s", r.Message)
+		case "DEVICES":
+			if len(r.Devices) == 0 {
+				return nil, fmt.Errorf("gpsd at %s has no GPS receiver attached", l.address)
+			}
+		case "TPV":
+			if r.Mode >= 2 && r.Lat != nil && r.Lon != nil {
+				return &Position{Latitude: *r.Lat, Longitude: *r.Lon, Source: PositionGPSD}, nil
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("gpsd at %s reported no position fix within %s", l.address, gpsdFixWait)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading from gpsd: %w", err)
+	}
+	return nil, fmt.Errorf("gpsd at %s closed the connection without a position fix", l.address)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultUserAgent identifies weatherornot to weather APIs. Some of them,
+// such as the National Weather Service and MET Norway, reject requests
+// without one.
+const DefaultUserAgent = "weatherornot (https://github.com/james-see/weatherornot)"
+
+const (
+	// defaultRetries is how many times a failed request is retried
+	defaultRetries = 2
+
+	// maxRetryWait is the longest Retry-After that is waited for
+	maxRetryWait = 10 * time.Second
+)
+
+// Requests as a whole are bounded by the caller's context, which --timeout
+// may set to any length. These bound the stages where a stalled server
+// would otherwise hang callers that set no deadline.
+var (
+	// dialTimeout bounds connecting to a server and the TLS handshake
+	dialTimeout = 10 * time.Second
+
+	// responseHeaderTimeout bounds waiting for a server to start answering
+	responseHeaderTimeout = 20 * time.Second
+)
+
+// retryBaseDelay is the delay before the first retry, doubled for each
+// further retry
+var retryBaseDelay = 500 * time.Millisecond
+
+// fetcher performs HTTP requests on behalf of a provider
+type fetcher struct {
+	httpClient *http.Client
+	userAgent  string
+	retries    int
+}
+
+// newFetcher creates a fetcher sending the given User-Agent. Connecting and
+// waiting for a response are bounded by dialTimeout and
+// responseHeaderTimeout even when the caller's context has no deadline.
+func newFetcher(userAgent string) *fetcher {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = dialTimeout
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+
+	return &fetcher{
+		httpClient: &http.Client{Transport: transport},
+		userAgent:  userAgent,
+		retries:    defaultRetries,
+	}
+}
+
+// get performs a GET request with the given extra headers. Network
+// errors, rate limiting and server errors are retried with jittered
+// exponential backoff, waiting as long as a Retry-After header asks.
+func (f *fetcher) get(ctx context.Context, apiURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := f.httpClient.Do(req)
+		if attempt >= f.retries {
+			return resp, err
+		}
+
+		wait := backoff(attempt)
+		if err == nil {
+			if !retryableStatus(resp.StatusCode) {
+				return resp, nil
+			}
+			if after := retryAfter(resp); after > 0 {
+				// Waiting longer than this is worse than failing
+				if after > maxRetryWait {
+					return resp, nil
+				}
+				wait = after
+			}
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// getJSON fetches apiURL and decodes the JSON response body into v
+func (f *fetcher) getJSON(ctx context.Context, apiURL string, v interface{}) error {
+	resp, err := f.get(ctx, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
+}
+
+// retryableStatus reports whether a response status may succeed if the
+// request is repeated
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before a retry, with full jitter so that many
+// clients do not retry in lockstep
+func backoff(attempt int) time.Duration {
+	max := retryBaseDelay << attempt
+	return time.Duration(rand.Int63n(int64(max) + 1))
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/james-see/weatherornot/internal/gazetteer"
+)
+
+// Ways of using the embedded gazetteer, set with the gazetteer config key
+const (
+	GazetteerOff      = "off"
+	GazetteerFallback = "fallback"
+	GazetteerPrimary  = "primary"
+)
+
+// localCandidates is how many gazetteer matches are offered for a name
+const localCandidates = 5
+
+// nearestCityKm is how far coordinates may be from a gazetteer city to be
+// labelled with it
+const nearestCityKm = 25
+
+// LocalGeocoder wraps a provider and resolves places with the embedded
+// gazetteer. As the primary geocoder it answers city lookups itself and
+// saves API calls; as a fallback it takes over when the provider cannot be
+// reached or there is no cached answer offline.
+type LocalGeocoder struct {
+	provider Provider
+	primary  bool
+}
+
+// NewLocalGeocoder creates a gazetteer-backed geocoding wrapper around
+// provider
+func NewLocalGeocoder(provider Provider, primary bool) *LocalGeocoder {
+	return &LocalGeocoder{provider: provider, primary: primary}
+}
+
+// Name returns the name of the wrapped provider
+func (g *LocalGeocoder) Name() string {
+	return g.provider.Name()
+}
+
+// GetCurrent fetches current weather by coordinates
+func (g *LocalGeocoder) GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return g.provider.GetCurrent(ctx, lat, lon)
+}
+
+// GetForecast fetches forecast data
+func (g *LocalGeocoder) GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return g.provider.GetForecast(ctx, lat, lon)
+}
+
+// Geocode converts city name to coordinates
+func (g *LocalGeocoder) Geocode(ctx context.Context, city, state, country string) (float64, float64, error) {
+	locs, err := g.SearchLocations(ctx, city, state, country)
+	if err != nil {
+		return 0, 0, err
+	}
+	return locs[0].Latitude, locs[0].Longitude, nil
+}
+
+// SearchLocations returns the places matching a city name
+func (g *LocalGeocoder) SearchLocations(ctx context.Context, city, state, country string) ([]Location, error) {
+	if g.primary {
+		if locs := localSearch(city, state, country); len(locs) > 0 {
+			return locs, nil
+		}
+		return g.provider.SearchLocations(ctx, city, state, country)
+	}
+
+	locs, err := g.provider.SearchLocations(ctx, city, state, country)
+	if err != nil && useLocal(err) {
+		if local := localSearch(city, state, country); len(local) > 0 {
+			return local, nil
+		}
+	}
+	return locs, err
+}
+
+// ReverseGeocode returns the place at coordinates, falling back to the
+// nearest gazetteer city
+func (g *LocalGeocoder) ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	loc, err := g.provider.ReverseGeocode(ctx, lat, lon)
+	if err != nil && useLocal(err) {
+		if c, ok := gazetteer.Nearest(lat, lon, nearestCityKm); ok {
+			local := cityLocation(c)
+			local.Latitude, local.Longitude = lat, lon
+			return &local, nil
+		}
+	}
+	return loc, err
+}
+
+// GetWeatherByZip fetches weather data by zip code
+func (g *LocalGeocoder) GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error) {
+	return g.provider.GetWeatherByZip(ctx, zip, countryCode)
+}
+
+// GetWeatherByCity fetches weather data by city name
+func (g *LocalGeocoder) GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error) {
+	if g.primary {
+		if locs := localSearch(city, state, country); len(locs) > 0 {
+			return g.weatherAt(ctx, locs[0])
+		}
+		return g.provider.GetWeatherByCity(ctx, city, state, country)
+	}
+
+	data, err := g.provider.GetWeatherByCity(ctx, city, state, country)
+	if err != nil && useLocal(err) {
+		if locs := localSearch(city, state, country); len(locs) > 0 {
+			return g.weatherAt(ctx, locs[0])
+		}
+	}
+	return data, err
+}
+
+// GetWeatherByCoords fetches weather data by coordinates
+func (g *LocalGeocoder) GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	return g.provider.GetWeatherByCoords(ctx, lat, lon)
+}
+
+// weatherAt fetches weather for a gazetteer place and keeps its name
+func (g *LocalGeocoder) weatherAt(ctx context.Context, loc Location) (*WeatherData, error) {
+	data, err := g.provider.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	data.Location.Name = loc.Name
+	data.Location.State = loc.State
+	data.Location.Country = loc.Country
+	return data, nil
+}
+
+// useLocal reports whether a provider error should be answered from the
+// gazetteer
+func useLocal(err error) bool {
+	return errors.Is(err, ErrNoCachedData) || IsUnavailable(err)
+}
+
+// localSearch looks a city up in the gazetteer
+func localSearch(city, state, country string) []Location {
+	matches := gazetteer.Search(city, state, country, localCandidates)
+	locs := make([]Location, len(matches))
+	for i, c := range matches {
+		locs[i] = cityLocation(c)
+	}
+	return locs
+}
+
+// cityLocation converts a gazetteer city to a Location
+func cityLocation(c gazetteer.City) Location {
+	return Location{
+		Name:      c.Name,
+		State:     c.Admin1,
+		Country:   c.Country,
+		Latitude:  c.Latitude,
+		Longitude: c.Longitude,
+		Timezone:  c.Timezone,
+	}
+}
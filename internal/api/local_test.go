@@ -0,0 +1,58 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/james-see/weatherornot/internal/api"
+	"github.com/james-see/weatherornot/internal/apitest"
+)
+
+func TestLocalGeocoderPrimary(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+	defer server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: server.Endpoints()})
+	local := api.NewLocalGeocoder(client, true)
+
+	data, err := local.GetWeatherByCity(ctx, "Springfield", "IL", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data.Location.Name != "Springfield" || data.Location.State != "Illinois" {
+		t.Errorf("Expected Springfield, Illinois, got %+v", data.Location)
+	}
+	for _, path := range server.Requests() {
+		if path != "/weather" && path != "/forecast" {
+			t.Errorf("Expected no geocoding request, got %s", path)
+		}
+	}
+}
+
+func TestLocalGeocoderFallback(t *testing.T) {
+	ctx := context.Background()
+	server := apitest.NewServer("")
+	endpoints := server.Endpoints()
+	server.Close()
+
+	client := api.NewClientWithOptions(api.ProviderOptions{APIKey: "any", Endpoints: endpoints})
+	local := api.NewLocalGeocoder(client, false)
+
+	locs, err := local.SearchLocations(ctx, "Denver", "CO", "")
+	if err != nil {
+		t.Fatalf("Expected the gazetteer to answer, got %v", err)
+	}
+	if locs[0].Name != "Denver" || locs[0].Country != "US" {
+		t.Errorf("Expected Denver, US, got %+v", locs[0])
+	}
+
+	loc, err := local.ReverseGeocode(ctx, 39.74, -104.99)
+	if err != nil || loc.Name != "Denver" {
+		t.Errorf("Expected Denver from the gazetteer, got %+v, %v", loc, err)
+	}
+
+	if _, err := local.SearchLocations(ctx, apitest.UnknownCity, "", ""); err == nil {
+		t.Error("Expected error for a city the gazetteer does not know either")
+	}
+}
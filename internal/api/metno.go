@@ -0,0 +1,399 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/cache"
+)
+
+const (
+	// ProviderMETNorway is the registry name of the MET Norway provider
+	ProviderMETNorway = "metno"
+
+	metNorwayURL = "https://api.met.no/weatherapi/locationforecast/2.0"
+
+	// metNorwayHours is how many hourly forecasts are kept
+	metNorwayHours = 48
+)
+
+func init() {
+	RegisterProvider(ProviderMETNorway, func(opts ProviderOptions) (Provider, error) {
+		return NewMETNorwayClient(opts), nil
+	})
+}
+
+// METNorwayClient is a client for the MET Norway locationforecast API.
+// It needs no API key, but MET Norway requires an identifying User-Agent
+// and clients that respect the Expires and Last-Modified headers, so
+// responses are cached on disk until they expire.
+type METNorwayClient struct {
+	http     *fetcher
+	geocoder *openMeteoGeocoder
+	units    string
+	store    *cache.Cache
+	baseURL  string
+
+	// refresh revalidates cached responses even before they expire
+	refresh bool
+}
+
+// metCacheEntry is a cached locationforecast response
+type metCacheEntry struct {
+	Expires      time.Time       `json:"expires"`
+	LastModified string          `json:"last_modified"`
+	Body         json.RawMessage `json:"body"`
+}
+
+// NewMETNorwayClient creates a new MET Norway client. Without a cache
+// directory every request downloads the forecast again.
+func NewMETNorwayClient(opts ProviderOptions) *METNorwayClient {
+	f := newFetcher(opts.UserAgent)
+
+	var store *cache.Cache
+	if opts.CacheDir != "" {
+		store = cache.New(filepath.Join(opts.CacheDir, ProviderMETNorway))
+	}
+
+	return &METNorwayClient{
+		http:     f,
+		geocoder: newOpenMeteoGeocoder(f, opts),
+		units:    opts.Units,
+		store:    store,
+		baseURL:  opts.endpoint(EndpointMETNorway, metNorwayURL),
+		refresh:  opts.Refresh,
+	}
+}
+
+// Name returns the registry name of the provider
+func (c *METNorwayClient) Name() string {
+	return ProviderMETNorway
+}
+
+// GetCurrent fetches current weather by coordinates
+func (c *METNorwayClient) GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	data, err := c.GetWeatherByCoords(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	data.Hourly = nil
+	data.Daily = nil
+	return data, nil
+}
+
+// GetForecast fetches forecast data
+func (c *METNorwayClient) GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	data, err := c.GetWeatherByCoords(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherData{
+		Hourly: data.Hourly,
+		Daily:  data.Daily,
+	}, nil
+}
+
+// Geocode converts city name to coordinates
+func (c *METNorwayClient) Geocode(ctx context.Context, city, state, country string) (float64, float64, error) {
+	loc, err := c.geocoder.geocodeCity(ctx, city, state, country)
+	if err != nil {
+		return 0, 0, err
+	}
+	return loc.Latitude, loc.Longitude, nil
+}
+
+// SearchLocations returns the places matching a city name
+func (c *METNorwayClient) SearchLocations(ctx context.Context, city, state, country string) ([]Location, error) {
+	return c.geocoder.searchCities(ctx, city, state, country)
+}
+
+// ReverseGeocode returns the place at coordinates
+func (c *METNorwayClient) ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	return c.geocoder.reverseGeocode(ctx, lat, lon)
+}
+
+// GetWeatherByZip fetches weather data by zip code
+func (c *METNorwayClient) GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error) {
+	loc, err := c.geocoder.geocodeZip(ctx, zip, countryCode)
+	if err != nil {
+		return nil, err
+	}
+	return c.weatherAt(ctx, loc)
+}
+
+// GetWeatherByCity fetches weather data by city name
+func (c *METNorwayClient) GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error) {
+	loc, err := c.geocoder.geocodeCity(ctx, city, state, country)
+	if err != nil {
+		return nil, err
+	}
+	return c.weatherAt(ctx, loc)
+}
+
+// GetWeatherByCoords fetches weather data by coordinates
+func (c *METNorwayClient) GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	resp, err := c.fetch(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	return c.parse(resp), nil
+}
+
+// weatherAt fetches weather for a geocoded location and keeps its name
+func (c *METNorwayClient) weatherAt(ctx context.Context, loc *Location) (*WeatherData, error) {
+	data, err := c.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	data.Location.Name = loc.Name
+	data.Location.State = loc.State
+	data.Location.Country = loc.Country
+	data.Location.Timezone = loc.Timezone
+	return data, nil
+}
+
+// fetch returns the locationforecast for coordinates. A cached response is
+// reused until its Expires time, or with refresh not at all; after that it
+// is revalidated with If-Modified-Since so an unchanged forecast is not
+// downloaded again.
+func (c *METNorwayClient) fetch(ctx context.Context, lat, lon float64) (*METNorwayResponse, error) {
+	// MET Norway asks for at most four decimals so responses can be cached
+	lat = math.Round(lat*1e4) / 1e4
+	lon = math.Round(lon*1e4) / 1e4
+
+	key := fmt.Sprintf("%.4f,%.4f", lat, lon)
+	entry := c.loadCache(key)
+	if entry != nil && !c.refresh && time.Now().Before(entry.Expires) {
+		return decodeMETNorway(entry.Body)
+	}
+
+	header := http.Header{}
+	if entry != nil && entry.LastModified != "" {
+		header.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	forecastURL := fmt.Sprintf("%s/complete?lat=%.4f&lon=%.4f", c.baseURL, lat, lon)
+	resp, err := c.http.get(ctx, forecastURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching weather data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		entry.Expires = metExpires(resp)
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNonAuthoritativeInfo:
+		// 203 marks a deprecated product version but still carries data
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching weather data: %w", err)
+		}
+		entry = &metCacheEntry{
+			Expires:      metExpires(resp),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Body:         body,
+		}
+	default:
+		return nil, fmt.Errorf("error fetching weather data: %w", statusError(resp))
+	}
+
+	c.saveCache(key, entry)
+	return decodeMETNorway(entry.Body)
+}
+
+// loadCache reads a cached response, returning nil when there is none
+func (c *METNorwayClient) loadCache(key string) *metCacheEntry {
+	if c.store == nil {
+		return nil
+	}
+
+	var entry metCacheEntry
+	if _, ok := c.store.Load(key, &entry); !ok {
+		return nil
+	}
+	return &entry
+}
+
+// saveCache writes a response to the cache. Failures only cost a download
+// on the next run, so they are ignored.
+func (c *METNorwayClient) saveCache(key string, entry *metCacheEntry) {
+	if c.store == nil {
+		return
+	}
+	_ = c.store.Put(key, entry)
+}
+
+// metExpires returns the Expires time of a response. Without a usable
+// header the response is treated as expired right away.
+func metExpires(resp *http.Response) time.Time {
+	expires, err := http.ParseTime(resp.Header.Get("Expires"))
+	if err != nil {
+		return time.Now()
+	}
+	return expires
+}
+
+// decodeMETNorway decodes a locationforecast response body
+func decodeMETNorway(body []byte) (*METNorwayResponse, error) {
+	var resp METNorwayResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(resp.Properties.Timeseries) == 0 {
+		return nil, fmt.Errorf("MET Norway returned no forecast data")
+	}
+	return &resp, nil
+}
+
+// parse converts a locationforecast response to our WeatherData model
+func (c *METNorwayClient) parse(resp *METNorwayResponse) *WeatherData {
+	series := resp.Properties.Timeseries
+	data := &WeatherData{
+		Hourly: make([]HourlyForecast, 0, metNorwayHours),
+		Daily:  make([]DailyForecast, 0),
+	}
+
+	if coords := resp.Geometry.Coordinates; len(coords) >= 2 {
+		data.Location.Longitude = coords[0]
+		data.Location.Latitude = coords[1]
+	}
+
+	// The first entry is the current hour
+	now := series[0]
+	details := now.Data.Instant.Details
+	cond := metCondition(metSymbol(now.Data.Next1Hours, now.Data.Next6Hours, now.Data.Next12Hours))
+	data.Current = CurrentWeather{
+		Temperature:   c.temp(details.AirTemperature),
+		FeelsLike:     c.temp(details.AirTemperature),
+		Humidity:      int(details.RelativeHumidity),
+		Pressure:      int(details.AirPressureAtSeaLevel),
+		WindSpeed:     c.speed(details.WindSpeed),
+		WindDegree:    int(details.WindFromDirection),
+		CloudCover:    int(details.CloudAreaFraction),
+		UVIndex:       details.UltravioletIndex,
+		Condition:     cond.description,
+		ConditionCode: cond.code,
+		Time:          now.Time,
+	}
+
+	// Hourly steps come first, later entries are six hours apart
+	start := time.Now().Truncate(time.Hour)
+	for _, entry := range series {
+		if entry.Data.Next1Hours == nil || entry.Time.Before(start) {
+			continue
+		}
+		if len(data.Hourly) >= metNorwayHours {
+			break
+		}
+
+		d := entry.Data.Instant.Details
+		cond := metCondition(entry.Data.Next1Hours.Summary.SymbolCode)
+		data.Hourly = append(data.Hourly, HourlyForecast{
+			Time:          entry.Time,
+			Temperature:   c.temp(d.AirTemperature),
+			FeelsLike:     c.temp(d.AirTemperature),
+			Humidity:      int(d.RelativeHumidity),
+			WindSpeed:     c.speed(d.WindSpeed),
+			Condition:     cond.description,
+			ConditionCode: cond.code,
+			PrecipChance:  int(entry.Data.Next1Hours.Details.ProbabilityOfPrecipitation),
+		})
+	}
+
+	// Aggregate the series into days. The condition of a day is the one
+	// forecast closest to local noon.
+	noonDistance := make(map[string]time.Duration)
+	dayIndex := make(map[string]int)
+	for _, entry := range series {
+		t := entry.Time.Local()
+		dateKey := t.Format("2006-01-02")
+		d := entry.Data.Instant.Details
+
+		idx, exists := dayIndex[dateKey]
+		if !exists {
+			idx = len(data.Daily)
+			dayIndex[dateKey] = idx
+			data.Daily = append(data.Daily, DailyForecast{
+				Date:    time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local),
+				TempMax: c.temp(d.AirTemperature),
+				TempMin: c.temp(d.AirTemperature),
+			})
+			noonDistance[dateKey] = math.MaxInt64
+		}
+		day := &data.Daily[idx]
+
+		temps := []float64{d.AirTemperature}
+		if p := entry.Data.Next6Hours; p != nil {
+			if p.Details.AirTemperatureMax != nil {
+				temps = append(temps, *p.Details.AirTemperatureMax)
+			}
+			if p.Details.AirTemperatureMin != nil {
+				temps = append(temps, *p.Details.AirTemperatureMin)
+			}
+		}
+		for _, temp := range temps {
+			day.TempMax = math.Max(day.TempMax, c.temp(temp))
+			day.TempMin = math.Min(day.TempMin, c.temp(temp))
+		}
+
+		day.WindSpeed = math.Max(day.WindSpeed, c.speed(d.WindSpeed))
+		day.UVIndex = math.Max(day.UVIndex, d.UltravioletIndex)
+		for _, p := range []*METNorwayPeriod{entry.Data.Next1Hours, entry.Data.Next6Hours, entry.Data.Next12Hours} {
+			if p != nil && int(p.Details.ProbabilityOfPrecipitation) > day.PrecipChance {
+				day.PrecipChance = int(p.Details.ProbabilityOfPrecipitation)
+			}
+		}
+
+		symbol := metSymbol(entry.Data.Next6Hours, entry.Data.Next12Hours, entry.Data.Next1Hours)
+		noon := time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, time.Local)
+		distance := t.Sub(noon)
+		if distance < 0 {
+			distance = -distance
+		}
+		if symbol != "" && distance < noonDistance[dateKey] {
+			noonDistance[dateKey] = distance
+			cond := metCondition(symbol)
+			day.Condition = cond.description
+			day.ConditionCode = cond.code
+			day.Humidity = int(d.RelativeHumidity)
+		}
+	}
+
+	return data
+}
+
+// metSymbol returns the first symbol code found in the given periods
+func metSymbol(periods ...*METNorwayPeriod) string {
+	for _, p := range periods {
+		if p != nil && p.Summary.SymbolCode != "" {
+			return p.Summary.SymbolCode
+		}
+	}
+	return ""
+}
+
+// temp converts a temperature from °C to the configured units
+func (c *METNorwayClient) temp(t float64) float64 {
+	switch c.units {
+	case "imperial":
+		return celsiusToFahrenheit(t)
+	case "standard":
+		return celsiusToKelvin(t)
+	default:
+		return t
+	}
+}
+
+// speed converts a wind speed from m/s to the configured units
+func (c *METNorwayClient) speed(ms float64) float64 {
+	if c.units == "imperial" {
+		return msToMph(ms)
+	}
+	return ms
+}
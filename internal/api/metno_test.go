@@ -0,0 +1,179 @@
+package api_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/api"
+)
+
+// metNorwayCounts counts the requests a MET Norway server answered
+type metNorwayCounts struct {
+	requests      int32
+	revalidations int32 // conditional requests answered with 304
+}
+
+// metNorwayServer serves the recorded locationforecast for Oslo, moved to
+// start tomorrow so no hour has passed yet. Conditional requests are
+// answered as not modified.
+func metNorwayServer(t *testing.T) (*httptest.Server, *metNorwayCounts) {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/metno_complete.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	body := strings.NewReplacer(
+		"{{DAY1}}", tomorrow.Format("2006-01-02"),
+		"{{DAY2}}", tomorrow.AddDate(0, 0, 1).Format("2006-01-02"),
+	).Replace(string(raw))
+
+	counts := &metNorwayCounts{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&counts.requests, 1)
+		if r.URL.Path != "/complete" || r.URL.Query().Get("lat") != "59.9139" || r.URL.Query().Get("lon") != "10.7522" {
+			t.Errorf("Expected /complete for 59.9139,10.7522, got %s", r.URL)
+		}
+		if r.Header.Get("User-Agent") != "weatherornot-test" {
+			t.Errorf("Expected the configured User-Agent, got %q", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		if r.Header.Get("If-Modified-Since") != "" {
+			atomic.AddInt32(&counts.revalidations, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, counts
+}
+
+func TestMETNorwayClient(t *testing.T) {
+	server, counts := metNorwayServer(t)
+	client := api.NewMETNorwayClient(api.ProviderOptions{
+		Units:     "imperial",
+		UserAgent: "weatherornot-test",
+		CacheDir:  t.TempDir(),
+		Endpoints: map[string]string{api.EndpointMETNorway: server.URL},
+	})
+
+	// Coordinates are rounded to the four decimals MET Norway asks for
+	data, err := client.GetWeatherByCoords(context.Background(), 59.913869, 10.752245)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if data.Location.Latitude != 59.9139 || data.Location.Longitude != 10.7522 {
+		t.Errorf("Expected 59.9139,10.7522 from the geometry, got %v,%v", data.Location.Latitude, data.Location.Longitude)
+	}
+
+	// MET Norway is always metric
+	cur := data.Current
+	if math.Abs(cur.Temperature-59) > 0.01 || math.Abs(cur.WindSpeed-8.95) > 0.01 {
+		t.Errorf("Expected 15°C as 59°F and 4 m/s as 8.95 mph, got %.2f and %.2f", cur.Temperature, cur.WindSpeed)
+	}
+	if cur.Humidity != 72 || cur.Pressure != 1013 || cur.WindDegree != 225 || cur.CloudCover != 45 || cur.UVIndex != 2.1 {
+		t.Errorf("Unexpected current conditions %+v", cur)
+	}
+	if cur.Condition != "partly cloudy" || cur.ConditionCode != 802 {
+		t.Errorf("Expected partly cloudy (802), got %q (%d)", cur.Condition, cur.ConditionCode)
+	}
+
+	// Only the entries with a next hour summary are hourly
+	if len(data.Hourly) != 3 {
+		t.Fatalf("Expected 3 hourly forecasts, got %d", len(data.Hourly))
+	}
+	if h := data.Hourly[1]; h.ConditionCode != 520 || h.PrecipChance != 35 {
+		t.Errorf("Expected light rain showers at 35%%, got %+v", h)
+	}
+
+	// Which days the entries fall on depends on the local time zone, but
+	// the extremes do not. Both come from six hour periods rather than
+	// the instant temperatures.
+	maxTemp, minTemp, maxPrecip := math.Inf(-1), math.Inf(1), 0
+	for _, day := range data.Daily {
+		maxTemp = math.Max(maxTemp, day.TempMax)
+		minTemp = math.Min(minTemp, day.TempMin)
+		if day.PrecipChance > maxPrecip {
+			maxPrecip = day.PrecipChance
+		}
+	}
+	if math.Abs(maxTemp-75.2) > 0.01 || math.Abs(minTemp-48.56) > 0.01 {
+		t.Errorf("Expected a high of 24°C (75.2°F) and a low of 9.2°C (48.56°F), got %.2f and %.2f", maxTemp, minTemp)
+	}
+	if maxPrecip != 65 {
+		t.Errorf("Expected the highest chance of precipitation to be 65%%, got %d", maxPrecip)
+	}
+
+	// The response has not expired, so it is read from the cache
+	if _, err := client.GetWeatherByCoords(context.Background(), 59.9139, 10.7522); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := atomic.LoadInt32(&counts.requests); n != 1 {
+		t.Errorf("Expected 1 request before the response expires, got %d", n)
+	}
+}
+
+func TestMETNorwayClientCache(t *testing.T) {
+	tests := []struct {
+		name              string
+		cache             bool
+		refresh           bool
+		wantRequests      int32
+		wantRevalidations int32
+	}{
+		{"cached", true, false, 1, 0},
+		{"refresh revalidates", true, true, 2, 1},
+		{"no cache", false, false, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Nothing may end up in the user's cache directory either
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CACHE_HOME", home)
+
+			server, counts := metNorwayServer(t)
+			opts := api.ProviderOptions{
+				UserAgent: "weatherornot-test",
+				Refresh:   tt.refresh,
+				Endpoints: map[string]string{api.EndpointMETNorway: server.URL},
+			}
+			if tt.cache {
+				opts.CacheDir = t.TempDir()
+			}
+			client := api.NewMETNorwayClient(opts)
+
+			for i := 0; i < 2; i++ {
+				data, err := client.GetWeatherByCoords(context.Background(), 59.9139, 10.7522)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(data.Hourly) != 3 {
+					t.Errorf("Expected 3 hourly forecasts, got %d", len(data.Hourly))
+				}
+			}
+
+			if n := atomic.LoadInt32(&counts.requests); n != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, n)
+			}
+			if n := atomic.LoadInt32(&counts.revalidations); n != tt.wantRevalidations {
+				t.Errorf("Expected %d revalidations, got %d", tt.wantRevalidations, n)
+			}
+			if entries, _ := os.ReadDir(home); len(entries) != 0 {
+				t.Errorf("Expected nothing written to the user cache, got %v", entries)
+			}
+		})
+	}
+}
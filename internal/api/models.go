@@ -8,11 +8,27 @@ type WeatherData struct {
 	Hourly   []HourlyForecast
 	Daily    []DailyForecast
 	Location Location
+
+	// Minutely precipitation for the next hour and government weather
+	// alerts; only filled by providers that offer them
+	Minutely []MinutelyForecast
+	Alerts   []WeatherAlert
+
+	// Source is the provider that served the data when a failover chain
+	// is used; Sources lists the providers merged into an ensemble forecast
+	Source  string
+	Sources []string
+
+	// FetchedAt is when the data was fetched from the provider; Stale is
+	// set when cached data is shown because fresh data is unavailable
+	FetchedAt time.Time
+	Stale     bool
 }
 
 // Location represents geographic location information
 type Location struct {
 	Name      string
+	State     string
 	Country   string
 	Latitude  float64
 	Longitude float64
@@ -49,6 +65,17 @@ type HourlyForecast struct {
 	ConditionCode   int
 	Icon            string
 	PrecipChance    int
+
+	// TempSpread is the range of temperatures reported by the Sources
+	// providers of an ensemble forecast
+	TempSpread      Spread
+	Sources         int
+}
+
+// Spread is the range of a value across ensemble members
+type Spread struct {
+	Min float64
+	Max float64
 }
 
 // DailyForecast represents daily weather forecast
@@ -62,8 +89,32 @@ type DailyForecast struct {
 	ConditionCode   int
 	Icon            string
 	PrecipChance    int
+	UVIndex         float64
 	Sunrise         time.Time
 	Sunset          time.Time
+
+	// Named forecast periods, e.g. "Thursday" and "Thursday Night",
+	// with their forecast text (National Weather Service only)
+	PeriodName            string
+	DetailedForecast      string
+	NightPeriodName       string
+	NightDetailedForecast string
+}
+
+// MinutelyForecast represents the precipitation forecast for one minute
+type MinutelyForecast struct {
+	Time          time.Time
+	Precipitation float64 // mm/h
+}
+
+// WeatherAlert represents a government weather alert
+type WeatherAlert struct {
+	SenderName  string
+	Event       string
+	Start       time.Time
+	End         time.Time
+	Description string
+	Tags        []string
 }
 
 // OpenWeatherMapResponse represents the response from OpenWeatherMap current weather API
@@ -164,3 +215,284 @@ type GeocodingResponse []struct {
 	State   string            `json:"state,omitempty"`
 }
 
+
+// OpenWeatherMapCondition is a weather condition in OpenWeatherMap responses
+type OpenWeatherMapCondition struct {
+	ID          int    `json:"id"`
+	Main        string `json:"main"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+}
+
+// OneCallResponse represents the response from the OpenWeatherMap One Call 3.0 API
+type OneCallResponse struct {
+	Lat            float64 `json:"lat"`
+	Lon            float64 `json:"lon"`
+	Timezone       string  `json:"timezone"`
+	TimezoneOffset int     `json:"timezone_offset"`
+	Current        struct {
+		Dt         int64                     `json:"dt"`
+		Sunrise    int64                     `json:"sunrise"`
+		Sunset     int64                     `json:"sunset"`
+		Temp       float64                   `json:"temp"`
+		FeelsLike  float64                   `json:"feels_like"`
+		Pressure   int                       `json:"pressure"`
+		Humidity   int                       `json:"humidity"`
+		UVI        float64                   `json:"uvi"`
+		Clouds     int                       `json:"clouds"`
+		Visibility int                       `json:"visibility"`
+		WindSpeed  float64                   `json:"wind_speed"`
+		WindDeg    int                       `json:"wind_deg"`
+		Weather    []OpenWeatherMapCondition `json:"weather"`
+	} `json:"current"`
+	Minutely []struct {
+		Dt            int64   `json:"dt"`
+		Precipitation float64 `json:"precipitation"`
+	} `json:"minutely"`
+	Hourly []struct {
+		Dt        int64                     `json:"dt"`
+		Temp      float64                   `json:"temp"`
+		FeelsLike float64                   `json:"feels_like"`
+		Humidity  int                       `json:"humidity"`
+		WindSpeed float64                   `json:"wind_speed"`
+		Weather   []OpenWeatherMapCondition `json:"weather"`
+		Pop       float64                   `json:"pop"`
+	} `json:"hourly"`
+	Daily []struct {
+		Dt      int64 `json:"dt"`
+		Sunrise int64 `json:"sunrise"`
+		Sunset  int64 `json:"sunset"`
+		Temp    struct {
+			Min float64 `json:"min"`
+			Max float64 `json:"max"`
+		} `json:"temp"`
+		Humidity  int                       `json:"humidity"`
+		WindSpeed float64                   `json:"wind_speed"`
+		Weather   []OpenWeatherMapCondition `json:"weather"`
+		Pop       float64                   `json:"pop"`
+		UVI       float64                   `json:"uvi"`
+	} `json:"daily"`
+	Alerts []struct {
+		SenderName  string   `json:"sender_name"`
+		Event       string   `json:"event"`
+		Start       int64    `json:"start"`
+		End         int64    `json:"end"`
+		Description string   `json:"description"`
+		Tags        []string `json:"tags"`
+	} `json:"alerts"`
+}
+
+// ZipGeocodingResponse represents the response from the OpenWeatherMap zip geocoding API
+type ZipGeocodingResponse struct {
+	Zip     string  `json:"zip"`
+	Name    string  `json:"name"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Country string  `json:"country"`
+}
+
+// OpenMeteoForecastResponse represents the response from the Open-Meteo forecast API
+type OpenMeteoForecastResponse struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	Timezone         string  `json:"timezone"`
+	UTCOffsetSeconds int     `json:"utc_offset_seconds"`
+	Current          struct {
+		Time                int64   `json:"time"`
+		Temperature         float64 `json:"temperature_2m"`
+		ApparentTemperature float64 `json:"apparent_temperature"`
+		Humidity            float64 `json:"relative_humidity_2m"`
+		PressureMSL         float64 `json:"pressure_msl"`
+		WindSpeed           float64 `json:"wind_speed_10m"`
+		WindDirection       float64 `json:"wind_direction_10m"`
+		CloudCover          float64 `json:"cloud_cover"`
+		Visibility          float64 `json:"visibility"`
+		UVIndex             float64 `json:"uv_index"`
+		WeatherCode         int     `json:"weather_code"`
+		IsDay               int     `json:"is_day"`
+	} `json:"current"`
+	Hourly struct {
+		Time                     []int64   `json:"time"`
+		Temperature              []float64 `json:"temperature_2m"`
+		ApparentTemperature      []float64 `json:"apparent_temperature"`
+		Humidity                 []float64 `json:"relative_humidity_2m"`
+		WindSpeed                []float64 `json:"wind_speed_10m"`
+		WeatherCode              []int     `json:"weather_code"`
+		PrecipitationProbability []float64 `json:"precipitation_probability"`
+	} `json:"hourly"`
+	Daily struct {
+		Time                        []int64   `json:"time"`
+		WeatherCode                 []int     `json:"weather_code"`
+		TemperatureMax              []float64 `json:"temperature_2m_max"`
+		TemperatureMin              []float64 `json:"temperature_2m_min"`
+		HumidityMean                []float64 `json:"relative_humidity_2m_mean"`
+		WindSpeedMax                []float64 `json:"wind_speed_10m_max"`
+		PrecipitationProbabilityMax []float64 `json:"precipitation_probability_max"`
+		UVIndexMax                  []float64 `json:"uv_index_max"`
+		Sunrise                     []int64   `json:"sunrise"`
+		Sunset                      []int64   `json:"sunset"`
+	} `json:"daily"`
+}
+
+// NominatimReverseResponse represents the response from the Nominatim
+// reverse geocoding API
+type NominatimReverseResponse struct {
+	Name    string `json:"name"`
+	Lat     string `json:"lat"`
+	Lon     string `json:"lon"`
+	Address struct {
+		City         string `json:"city"`
+		Town         string `json:"town"`
+		Village      string `json:"village"`
+		Hamlet       string `json:"hamlet"`
+		Municipality string `json:"municipality"`
+		County       string `json:"county"`
+		State        string `json:"state"`
+		CountryCode  string `json:"country_code"`
+	} `json:"address"`
+	Error string `json:"error"`
+}
+
+// OpenMeteoGeocodingResponse represents the response from the Open-Meteo geocoding API
+type OpenMeteoGeocodingResponse struct {
+	Results []struct {
+		Name        string   `json:"name"`
+		Latitude    float64  `json:"latitude"`
+		Longitude   float64  `json:"longitude"`
+		CountryCode string   `json:"country_code"`
+		Country     string   `json:"country"`
+		Admin1      string   `json:"admin1"`
+		Timezone    string   `json:"timezone"`
+		Population  int      `json:"population"`
+		Postcodes   []string `json:"postcodes"`
+	} `json:"results"`
+}
+
+// NWSPointResponse represents the response from the NWS /points endpoint
+type NWSPointResponse struct {
+	Properties struct {
+		GridID              string `json:"gridId"`
+		GridX               int    `json:"gridX"`
+		GridY               int    `json:"gridY"`
+		Forecast            string `json:"forecast"`
+		ForecastHourly      string `json:"forecastHourly"`
+		ObservationStations string `json:"observationStations"`
+		TimeZone            string `json:"timeZone"`
+		RelativeLocation    struct {
+			Properties struct {
+				City  string `json:"city"`
+				State string `json:"state"`
+			} `json:"properties"`
+		} `json:"relativeLocation"`
+	} `json:"properties"`
+}
+
+// NWSValue is a quantitative NWS value; Value is nil when unavailable
+type NWSValue struct {
+	UnitCode string   `json:"unitCode"`
+	Value    *float64 `json:"value"`
+}
+
+// NWSForecastResponse represents the response from the NWS forecast and
+// hourly forecast endpoints
+type NWSForecastResponse struct {
+	Properties struct {
+		Periods []struct {
+			Number                     int       `json:"number"`
+			Name                       string    `json:"name"`
+			StartTime                  time.Time `json:"startTime"`
+			EndTime                    time.Time `json:"endTime"`
+			IsDaytime                  bool      `json:"isDaytime"`
+			Temperature                float64   `json:"temperature"`
+			TemperatureUnit            string    `json:"temperatureUnit"`
+			ProbabilityOfPrecipitation NWSValue  `json:"probabilityOfPrecipitation"`
+			RelativeHumidity           NWSValue  `json:"relativeHumidity"`
+			WindSpeed                  string    `json:"windSpeed"`
+			WindDirection              string    `json:"windDirection"`
+			Icon                       string    `json:"icon"`
+			ShortForecast              string    `json:"shortForecast"`
+			DetailedForecast           string    `json:"detailedForecast"`
+		} `json:"periods"`
+	} `json:"properties"`
+}
+
+// NWSStationsResponse represents the response from the NWS observation
+// stations endpoint
+type NWSStationsResponse struct {
+	Features []struct {
+		Properties struct {
+			StationIdentifier string `json:"stationIdentifier"`
+			Name              string `json:"name"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+// NWSObservationResponse represents the response from the NWS latest
+// observation endpoint
+type NWSObservationResponse struct {
+	Properties struct {
+		Timestamp          time.Time `json:"timestamp"`
+		TextDescription    string    `json:"textDescription"`
+		Icon               string    `json:"icon"`
+		Temperature        NWSValue  `json:"temperature"`
+		WindChill          NWSValue  `json:"windChill"`
+		HeatIndex          NWSValue  `json:"heatIndex"`
+		RelativeHumidity   NWSValue  `json:"relativeHumidity"`
+		WindSpeed          NWSValue  `json:"windSpeed"`
+		WindDirection      NWSValue  `json:"windDirection"`
+		BarometricPressure NWSValue  `json:"barometricPressure"`
+		Visibility         NWSValue  `json:"visibility"`
+		CloudLayers        []NWSCloudLayer `json:"cloudLayers"`
+	} `json:"properties"`
+}
+
+// NWSCloudLayer is a cloud layer reported in an NWS observation
+type NWSCloudLayer struct {
+	Amount string `json:"amount"`
+}
+
+// METNorwayResponse represents the response from the MET Norway
+// locationforecast 2.0 "complete" product
+type METNorwayResponse struct {
+	Geometry struct {
+		Coordinates []float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties struct {
+		Meta struct {
+			UpdatedAt time.Time `json:"updated_at"`
+		} `json:"meta"`
+		Timeseries []struct {
+			Time time.Time `json:"time"`
+			Data struct {
+				Instant struct {
+					Details struct {
+						AirPressureAtSeaLevel float64 `json:"air_pressure_at_sea_level"`
+						AirTemperature        float64 `json:"air_temperature"`
+						CloudAreaFraction     float64 `json:"cloud_area_fraction"`
+						FogAreaFraction       float64 `json:"fog_area_fraction"`
+						RelativeHumidity      float64 `json:"relative_humidity"`
+						UltravioletIndex      float64 `json:"ultraviolet_index_clear_sky"`
+						WindFromDirection     float64 `json:"wind_from_direction"`
+						WindSpeed             float64 `json:"wind_speed"`
+					} `json:"details"`
+				} `json:"instant"`
+				Next1Hours  *METNorwayPeriod `json:"next_1_hours"`
+				Next6Hours  *METNorwayPeriod `json:"next_6_hours"`
+				Next12Hours *METNorwayPeriod `json:"next_12_hours"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"properties"`
+}
+
+// METNorwayPeriod is a MET Norway forecast summary for the following hours
+type METNorwayPeriod struct {
+	Summary struct {
+		SymbolCode string `json:"symbol_code"`
+	} `json:"summary"`
+	Details struct {
+		AirTemperatureMax          *float64 `json:"air_temperature_max"`
+		AirTemperatureMin          *float64 `json:"air_temperature_min"`
+		PrecipitationAmount        float64  `json:"precipitation_amount"`
+		ProbabilityOfPrecipitation float64  `json:"probability_of_precipitation"`
+	} `json:"details"`
+}
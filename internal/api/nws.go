@@ -0,0 +1,469 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ProviderNWS is the registry name of the National Weather Service provider
+	ProviderNWS = "nws"
+
+	nwsURL = "https://api.weather.gov"
+
+	// nwsHours is how many hourly forecasts are kept
+	nwsHours = 48
+)
+
+func init() {
+	RegisterProvider(ProviderNWS, func(opts ProviderOptions) (Provider, error) {
+		return NewNWSClient(opts), nil
+	})
+}
+
+// NWSClient is a client for the US National Weather Service API
+// (api.weather.gov). It covers US locations only and needs no API key.
+type NWSClient struct {
+	http     *fetcher
+	geocoder *openMeteoGeocoder
+	units    string
+	baseURL  string
+}
+
+// NewNWSClient creates a new National Weather Service client
+func NewNWSClient(opts ProviderOptions) *NWSClient {
+	f := newFetcher(opts.UserAgent)
+	return &NWSClient{
+		http:     f,
+		geocoder: newOpenMeteoGeocoder(f, opts),
+		units:    opts.Units,
+		baseURL:  opts.endpoint(EndpointNWS, nwsURL),
+	}
+}
+
+// Name returns the registry name of the provider
+func (c *NWSClient) Name() string {
+	return ProviderNWS
+}
+
+// GetCurrent fetches the latest observation from the nearest station
+func (c *NWSClient) GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	point, err := c.getPoint(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	hourly, err := c.getForecast(ctx, point.Properties.ForecastHourly)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &WeatherData{Location: c.parseLocation(point, lat, lon)}
+	data.Current = c.parseCurrent(ctx, point, hourly)
+	return data, nil
+}
+
+// GetForecast fetches the hourly and 12-hour period forecasts
+func (c *NWSClient) GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	point, err := c.getPoint(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	hourly, err := c.getForecast(ctx, point.Properties.ForecastHourly)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := c.getForecast(ctx, point.Properties.Forecast)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WeatherData{
+		Hourly: c.parseHourly(hourly),
+		Daily:  c.parseDaily(periods),
+	}, nil
+}
+
+// Geocode converts city name to coordinates
+func (c *NWSClient) Geocode(ctx context.Context, city, state, country string) (float64, float64, error) {
+	loc, err := c.geocoder.geocodeCity(ctx, city, state, country)
+	if err != nil {
+		return 0, 0, err
+	}
+	return loc.Latitude, loc.Longitude, nil
+}
+
+// SearchLocations returns the places matching a city name
+func (c *NWSClient) SearchLocations(ctx context.Context, city, state, country string) ([]Location, error) {
+	return c.geocoder.searchCities(ctx, city, state, country)
+}
+
+// ReverseGeocode returns the place at coordinates. Inside the US this is
+// the nearest town NWS reports for the gridpoint.
+func (c *NWSClient) ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	point, err := c.getPoint(ctx, lat, lon)
+	if err != nil || point.Properties.RelativeLocation.Properties.City == "" {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return c.geocoder.reverseGeocode(ctx, lat, lon)
+	}
+
+	rel := point.Properties.RelativeLocation.Properties
+	return &Location{
+		Name:      rel.City,
+		State:     rel.State,
+		Country:   "US",
+		Latitude:  lat,
+		Longitude: lon,
+		Timezone:  point.Properties.TimeZone,
+	}, nil
+}
+
+// GetWeatherByZip fetches weather data by zip code
+func (c *NWSClient) GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error) {
+	loc, err := c.geocoder.geocodeZip(ctx, zip, countryCode)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
+}
+
+// GetWeatherByCity fetches weather data by city name
+func (c *NWSClient) GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error) {
+	loc, err := c.geocoder.geocodeCity(ctx, city, state, country)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
+}
+
+// GetWeatherByCoords fetches weather data by coordinates. The gridpoint is
+// resolved once and shared by the current conditions and forecasts.
+func (c *NWSClient) GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	point, err := c.getPoint(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	hourly, err := c.getForecast(ctx, point.Properties.ForecastHourly)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := c.getForecast(ctx, point.Properties.Forecast)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WeatherData{
+		Location: c.parseLocation(point, lat, lon),
+		Current:  c.parseCurrent(ctx, point, hourly),
+		Hourly:   c.parseHourly(hourly),
+		Daily:    c.parseDaily(periods),
+	}, nil
+}
+
+// getPoint resolves coordinates to an NWS forecast gridpoint
+func (c *NWSClient) getPoint(ctx context.Context, lat, lon float64) (*NWSPointResponse, error) {
+	// NWS redirects requests with more than four decimal places
+	pointURL := fmt.Sprintf("%s/points/%.4f,%.4f", c.baseURL, lat, lon)
+
+	var point NWSPointResponse
+	if err := c.http.getJSON(ctx, pointURL, &point); err != nil {
+		return nil, fmt.Errorf("error resolving NWS gridpoint: %w", err)
+	}
+
+	if point.Properties.Forecast == "" || point.Properties.ForecastHourly == "" {
+		return nil, fmt.Errorf("no NWS forecast available for %.4f,%.4f", lat, lon)
+	}
+
+	return &point, nil
+}
+
+// getForecast fetches a forecast URL returned by the /points endpoint
+func (c *NWSClient) getForecast(ctx context.Context, forecastURL string) (*NWSForecastResponse, error) {
+	units := "si"
+	if c.units == "imperial" {
+		units = "us"
+	}
+
+	var forecast NWSForecastResponse
+	if err := c.http.getJSON(ctx, forecastURL+"?units="+units, &forecast); err != nil {
+		return nil, fmt.Errorf("error fetching forecast data: %w", err)
+	}
+
+	return &forecast, nil
+}
+
+// getObservation fetches the latest observation from the station closest
+// to the gridpoint
+func (c *NWSClient) getObservation(ctx context.Context, stationsURL string) (*NWSObservationResponse, error) {
+	var stations NWSStationsResponse
+	if err := c.http.getJSON(ctx, stationsURL, &stations); err != nil {
+		return nil, fmt.Errorf("error fetching observation stations: %w", err)
+	}
+
+	if len(stations.Features) == 0 {
+		return nil, fmt.Errorf("no observation stations found")
+	}
+
+	station := stations.Features[0].Properties.StationIdentifier
+	obsURL := fmt.Sprintf("%s/stations/%s/observations/latest", c.baseURL, station)
+
+	var obs NWSObservationResponse
+	if err := c.http.getJSON(ctx, obsURL, &obs); err != nil {
+		return nil, fmt.Errorf("error fetching observation: %w", err)
+	}
+
+	return &obs, nil
+}
+
+// parseLocation builds the location from the gridpoint metadata
+func (c *NWSClient) parseLocation(point *NWSPointResponse, lat, lon float64) Location {
+	rel := point.Properties.RelativeLocation.Properties
+	return Location{
+		Name:      rel.City,
+		State:     rel.State,
+		Country:   "US",
+		Latitude:  lat,
+		Longitude: lon,
+		Timezone:  point.Properties.TimeZone,
+	}
+}
+
+// parseCurrent builds current conditions from the latest station
+// observation. Stations report sparsely, so missing values and failed
+// observation requests fall back to the first hourly forecast period.
+func (c *NWSClient) parseCurrent(ctx context.Context, point *NWSPointResponse, hourly *NWSForecastResponse) CurrentWeather {
+	var current CurrentWeather
+
+	if periods := hourly.Properties.Periods; len(periods) > 0 {
+		p := periods[0]
+		current = CurrentWeather{
+			Temperature:   c.temp(p.Temperature),
+			FeelsLike:     c.temp(p.Temperature),
+			Humidity:      int(nwsValue(p.RelativeHumidity, 0)),
+			WindSpeed:     c.parseWindSpeed(p.WindSpeed),
+			Condition:     p.ShortForecast,
+			ConditionCode: nwsConditionCode(p.Icon),
+			Time:          p.StartTime,
+		}
+	}
+
+	obs, err := c.getObservation(ctx, point.Properties.ObservationStations)
+	if err != nil {
+		return current
+	}
+
+	o := obs.Properties
+	if o.Temperature.Value == nil {
+		return current
+	}
+
+	current.Temperature = c.tempFromCelsius(*o.Temperature.Value)
+	current.FeelsLike = current.Temperature
+	if o.WindChill.Value != nil {
+		current.FeelsLike = c.tempFromCelsius(*o.WindChill.Value)
+	} else if o.HeatIndex.Value != nil {
+		current.FeelsLike = c.tempFromCelsius(*o.HeatIndex.Value)
+	}
+	if o.RelativeHumidity.Value != nil {
+		current.Humidity = int(*o.RelativeHumidity.Value)
+	}
+	if o.WindSpeed.Value != nil {
+		current.WindSpeed = c.speedFromKmh(*o.WindSpeed.Value)
+	}
+	current.WindDegree = int(nwsValue(o.WindDirection, 0))
+	current.Pressure = int(nwsValue(o.BarometricPressure, 0) / 100)
+	current.Visibility = int(nwsValue(o.Visibility, 0))
+	current.CloudCover = nwsCloudCover(o.CloudLayers)
+	if o.TextDescription != "" {
+		current.Condition = o.TextDescription
+		current.ConditionCode = nwsConditionCode(o.Icon)
+	}
+	current.Time = o.Timestamp
+
+	return current
+}
+
+// parseHourly converts the hourly forecast to our model
+func (c *NWSClient) parseHourly(resp *NWSForecastResponse) []HourlyForecast {
+	hourly := make([]HourlyForecast, 0, nwsHours)
+
+	for _, p := range resp.Properties.Periods {
+		if len(hourly) >= nwsHours {
+			break
+		}
+
+		hourly = append(hourly, HourlyForecast{
+			Time:          p.StartTime,
+			Temperature:   c.temp(p.Temperature),
+			FeelsLike:     c.temp(p.Temperature),
+			Humidity:      int(nwsValue(p.RelativeHumidity, 0)),
+			WindSpeed:     c.parseWindSpeed(p.WindSpeed),
+			Condition:     p.ShortForecast,
+			ConditionCode: nwsConditionCode(p.Icon),
+			PrecipChance:  int(nwsValue(p.ProbabilityOfPrecipitation, 0)),
+		})
+	}
+
+	return hourly
+}
+
+// parseDaily pairs the 12-hour day and night periods into daily forecasts
+func (c *NWSClient) parseDaily(resp *NWSForecastResponse) []DailyForecast {
+	daily := make([]DailyForecast, 0)
+
+	for _, p := range resp.Properties.Periods {
+		temp := c.temp(p.Temperature)
+		precip := int(nwsValue(p.ProbabilityOfPrecipitation, 0))
+
+		// A night period completes the preceding day. When the forecast
+		// starts at night ("Tonight"), it gets a day of its own.
+		if !p.IsDaytime && len(daily) > 0 && daily[len(daily)-1].NightPeriodName == "" {
+			day := &daily[len(daily)-1]
+			day.TempMin = temp
+			day.NightPeriodName = p.Name
+			day.NightDetailedForecast = p.DetailedForecast
+			if precip > day.PrecipChance {
+				day.PrecipChance = precip
+			}
+			continue
+		}
+
+		day := DailyForecast{
+			Date:          p.StartTime,
+			TempMax:       temp,
+			TempMin:       temp,
+			Humidity:      int(nwsValue(p.RelativeHumidity, 0)),
+			WindSpeed:     c.parseWindSpeed(p.WindSpeed),
+			Condition:     p.ShortForecast,
+			ConditionCode: nwsConditionCode(p.Icon),
+			PrecipChance:  precip,
+		}
+		if p.IsDaytime {
+			day.PeriodName = p.Name
+			day.DetailedForecast = p.DetailedForecast
+		} else {
+			day.NightPeriodName = p.Name
+			day.NightDetailedForecast = p.DetailedForecast
+		}
+		daily = append(daily, day)
+	}
+
+	return daily
+}
+
+// temp converts a forecast temperature (°F for imperial, °C otherwise)
+func (c *NWSClient) temp(t float64) float64 {
+	if c.units == "standard" {
+		return celsiusToKelvin(t)
+	}
+	return t
+}
+
+// tempFromCelsius converts an observed temperature, always in °C
+func (c *NWSClient) tempFromCelsius(t float64) float64 {
+	switch c.units {
+	case "imperial":
+		return celsiusToFahrenheit(t)
+	case "standard":
+		return celsiusToKelvin(t)
+	default:
+		return t
+	}
+}
+
+// speedFromKmh converts an observed wind speed, always in km/h
+func (c *NWSClient) speedFromKmh(kmh float64) float64 {
+	if c.units == "imperial" {
+		return kmhToMph(kmh)
+	}
+	return kmhToMs(kmh)
+}
+
+var windSpeedRegex = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// parseWindSpeed parses forecast wind speeds such as "10 mph" or
+// "15 to 25 km/h", using the upper value of a range
+func (c *NWSClient) parseWindSpeed(s string) float64 {
+	var speed float64
+	for _, m := range windSpeedRegex.FindAllString(s, -1) {
+		if v, err := strconv.ParseFloat(m, 64); err == nil && v > speed {
+			speed = v
+		}
+	}
+
+	if strings.Contains(s, "km/h") {
+		return c.speedFromKmh(speed)
+	}
+	return speed
+}
+
+// nwsValue returns the value of v, or def when it is unavailable
+func nwsValue(v NWSValue, def float64) float64 {
+	if v.Value == nil {
+		return def
+	}
+	return *v.Value
+}
+
+// nwsCloudCover approximates cloud cover from the highest reported layer amount
+func nwsCloudCover(layers []NWSCloudLayer) int {
+	cover := 0
+	for _, layer := range layers {
+		var pct int
+		switch layer.Amount {
+		case "FEW":
+			pct = 20
+		case "SCT":
+			pct = 40
+		case "BKN":
+			pct = 75
+		case "OVC", "VV":
+			pct = 100
+		}
+		if pct > cover {
+			cover = pct
+		}
+	}
+	return cover
+}
+
+// nwsConditions maps NWS icon codes to OpenWeatherMap condition codes
+var nwsConditions = map[string]int{
+	"skc": 800, "few": 801, "sct": 802, "bkn": 803, "ovc": 804,
+	"wind_skc": 800, "wind_few": 801, "wind_sct": 802, "wind_bkn": 803, "wind_ovc": 804,
+	"snow": 601, "blizzard": 602, "rain_snow": 616, "rain_sleet": 611, "snow_sleet": 611,
+	"sleet": 611, "fzra": 511, "rain_fzra": 511, "snow_fzra": 511,
+	"rain": 501, "rain_showers": 521, "rain_showers_hi": 520,
+	"tsra": 211, "tsra_sct": 210, "tsra_hi": 210,
+	"tornado": 781, "hurricane": 781, "tropical_storm": 771,
+	"dust": 761, "smoke": 711, "haze": 721, "fog": 741,
+	"hot": 800, "cold": 800,
+}
+
+// nwsConditionCode extracts the condition from an NWS icon URL such as
+// https://api.weather.gov/icons/land/day/tsra_hi,20/rain,40?size=small
+func nwsConditionCode(icon string) int {
+	icon = strings.SplitN(icon, "?", 2)[0]
+	idx := strings.Index(icon, "/land/")
+	if idx < 0 {
+		return 0
+	}
+
+	// Skip "day/" or "night/" and take the first condition of the period
+	parts := strings.Split(icon[idx+len("/land/"):], "/")
+	if len(parts) < 2 {
+		return 0
+	}
+	code := strings.SplitN(parts[1], ",", 2)[0]
+
+	return nwsConditions[code]
+}
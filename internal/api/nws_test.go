@@ -0,0 +1,144 @@
+package api_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/james-see/weatherornot/internal/api"
+)
+
+// nwsServer serves recorded NWS responses for the gridpoint of central
+// New York. The gridpoint links to the server itself, as the real API
+// links to its own forecast and station URLs.
+func nwsServer(t *testing.T, wantUnits string) (*httptest.Server, func() []string) {
+	t.Helper()
+	routes := map[string]string{
+		"/points/40.7128,-74.0060":              "nws_points.json",
+		"/gridpoints/OKX/33,35/forecast":        "nws_forecast.json",
+		"/gridpoints/OKX/33,35/forecast/hourly": "nws_forecast_hourly.json",
+		"/gridpoints/OKX/33,35/stations":        "nws_stations.json",
+		"/stations/KNYC/observations/latest":    "nws_observation.json",
+	}
+
+	var mu sync.Mutex
+	var paths []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		name, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/forecast") && r.URL.Query().Get("units") != wantUnits {
+			t.Errorf("Expected units=%s for %s, got %q", wantUnits, r.URL.Path, r.URL.Query().Get("units"))
+		}
+		body, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(strings.ReplaceAll(string(body), "{{BASE}}", server.URL)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func TestNWSClient(t *testing.T) {
+	server, requests := nwsServer(t, "us")
+	client := api.NewNWSClient(api.ProviderOptions{
+		Units:     "imperial",
+		Endpoints: map[string]string{api.EndpointNWS: server.URL},
+	})
+
+	data, err := client.GetWeatherByCoords(context.Background(), 40.7128, -74.006)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The gridpoint leads to both forecasts and the nearest station
+	for _, path := range []string{
+		"/points/40.7128,-74.0060",
+		"/gridpoints/OKX/33,35/forecast/hourly",
+		"/gridpoints/OKX/33,35/forecast",
+		"/gridpoints/OKX/33,35/stations",
+		"/stations/KNYC/observations/latest",
+	} {
+		found := false
+		for _, p := range requests() {
+			found = found || p == path
+		}
+		if !found {
+			t.Errorf("Expected a request for %s, got %v", path, requests())
+		}
+	}
+
+	if loc := data.Location; loc.Name != "New York" || loc.State != "NY" || loc.Country != "US" {
+		t.Errorf("Expected New York, NY, US, got %q, %q, %q", loc.Name, loc.State, loc.Country)
+	}
+
+	// Observations are metric whatever the units asked for
+	cur := data.Current
+	if math.Abs(cur.Temperature-71.96) > 0.01 || cur.FeelsLike != cur.Temperature {
+		t.Errorf("Expected 22.2°C as 71.96°F, got %.2f (feels like %.2f)", cur.Temperature, cur.FeelsLike)
+	}
+	if math.Abs(cur.WindSpeed-10.29) > 0.01 {
+		t.Errorf("Expected 16.56 km/h as 10.29 mph, got %.2f", cur.WindSpeed)
+	}
+	if cur.Humidity != 61 || cur.Pressure != 1014 || cur.WindDegree != 210 || cur.Visibility != 16090 || cur.CloudCover != 75 {
+		t.Errorf("Unexpected current conditions %+v", cur)
+	}
+	if cur.Condition != "Mostly Cloudy" || cur.ConditionCode != 803 {
+		t.Errorf("Expected Mostly Cloudy (803), got %q (%d)", cur.Condition, cur.ConditionCode)
+	}
+
+	if len(data.Hourly) != 3 {
+		t.Fatalf("Expected 3 hourly forecasts, got %d", len(data.Hourly))
+	}
+	if h := data.Hourly[2]; h.Temperature != 74 || h.WindSpeed != 10 || h.ConditionCode != 521 || h.PrecipChance != 15 {
+		t.Errorf("Unexpected hourly forecast %+v", h)
+	}
+
+	// Day and night periods pair up into days
+	if len(data.Daily) != 2 {
+		t.Fatalf("Expected 2 daily forecasts, got %d", len(data.Daily))
+	}
+	today := data.Daily[0]
+	if today.TempMax != 75 || today.TempMin != 60 || today.PrecipChance != 40 || today.ConditionCode != 802 {
+		t.Errorf("Unexpected forecast for today %+v", today)
+	}
+	if today.PeriodName != "This Afternoon" || today.NightPeriodName != "Tonight" {
+		t.Errorf("Expected This Afternoon and Tonight, got %q and %q", today.PeriodName, today.NightPeriodName)
+	}
+	if monday := data.Daily[1]; monday.TempMax != 81 || monday.TempMin != 64 || monday.WindSpeed != 15 || monday.ConditionCode != 210 {
+		t.Errorf("Unexpected forecast for Monday %+v", monday)
+	}
+}
+
+func TestNWSClientReverseGeocode(t *testing.T) {
+	server, _ := nwsServer(t, "si")
+	client := api.NewNWSClient(api.ProviderOptions{Endpoints: map[string]string{api.EndpointNWS: server.URL}})
+
+	loc, err := client.ReverseGeocode(context.Background(), 40.7128, -74.006)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if loc.Name != "New York" || loc.State != "NY" || loc.Timezone != "America/New_York" {
+		t.Errorf("Expected New York, NY in America/New_York, got %+v", loc)
+	}
+}
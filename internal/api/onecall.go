@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const openWeatherMapOneCallURL = "https://api.openweathermap.org/data/3.0"
+
+// fetchOneCall fetches the One Call 3.0 API. exclude lists the parts of
+// the response to leave out, e.g. "minutely,alerts".
+func (c *Client) fetchOneCall(ctx context.Context, lat, lon float64, exclude string) (*WeatherData, error) {
+	oneCallReqURL := fmt.Sprintf("%s/onecall?lat=%f&lon=%f&appid=%s&units=%s",
+		c.oneCallURL, lat, lon, c.apiKey, c.units)
+	if exclude != "" {
+		oneCallReqURL += "&exclude=" + exclude
+	}
+
+	var resp OneCallResponse
+	if err := c.http.getJSON(ctx, oneCallReqURL, &resp); err != nil {
+		return nil, fmt.Errorf("error fetching weather data: %w", err)
+	}
+
+	return c.parseOneCall(&resp), nil
+}
+
+// oneCallAt fetches One Call data for a geocoded location and keeps its
+// name, which One Call responses do not include
+func (c *Client) oneCallAt(ctx context.Context, loc *Location) (*WeatherData, error) {
+	data, err := c.fetchOneCall(ctx, loc.Latitude, loc.Longitude, "")
+	if err != nil {
+		return nil, err
+	}
+	data.Location.Name = loc.Name
+	data.Location.State = loc.State
+	data.Location.Country = loc.Country
+	return data, nil
+}
+
+// parseOneCall converts a One Call response to our WeatherData model
+func (c *Client) parseOneCall(resp *OneCallResponse) *WeatherData {
+	cur := resp.Current
+	cond := firstCondition(cur.Weather)
+
+	data := &WeatherData{
+		Location: Location{
+			Latitude:  resp.Lat,
+			Longitude: resp.Lon,
+			Timezone:  resp.Timezone,
+		},
+		Current: CurrentWeather{
+			Temperature:   cur.Temp,
+			FeelsLike:     cur.FeelsLike,
+			Humidity:      cur.Humidity,
+			Pressure:      cur.Pressure,
+			WindSpeed:     cur.WindSpeed,
+			WindDegree:    cur.WindDeg,
+			Visibility:    cur.Visibility,
+			CloudCover:    cur.Clouds,
+			UVIndex:       cur.UVI,
+			Condition:     cond.Description,
+			ConditionCode: cond.ID,
+			Icon:          cond.Icon,
+			Sunrise:       time.Unix(cur.Sunrise, 0),
+			Sunset:        time.Unix(cur.Sunset, 0),
+			Time:          time.Unix(cur.Dt, 0),
+		},
+		Hourly:   make([]HourlyForecast, 0, len(resp.Hourly)),
+		Daily:    make([]DailyForecast, 0, len(resp.Daily)),
+		Minutely: make([]MinutelyForecast, 0, len(resp.Minutely)),
+		Alerts:   make([]WeatherAlert, 0, len(resp.Alerts)),
+	}
+
+	for _, m := range resp.Minutely {
+		data.Minutely = append(data.Minutely, MinutelyForecast{
+			Time:          time.Unix(m.Dt, 0),
+			Precipitation: m.Precipitation,
+		})
+	}
+
+	for _, h := range resp.Hourly {
+		cond := firstCondition(h.Weather)
+		data.Hourly = append(data.Hourly, HourlyForecast{
+			Time:          time.Unix(h.Dt, 0),
+			Temperature:   h.Temp,
+			FeelsLike:     h.FeelsLike,
+			Humidity:      h.Humidity,
+			WindSpeed:     h.WindSpeed,
+			Condition:     cond.Description,
+			ConditionCode: cond.ID,
+			Icon:          cond.Icon,
+			PrecipChance:  int(h.Pop * 100),
+		})
+	}
+
+	for _, d := range resp.Daily {
+		cond := firstCondition(d.Weather)
+		data.Daily = append(data.Daily, DailyForecast{
+			Date:          time.Unix(d.Dt, 0),
+			TempMax:       d.Temp.Max,
+			TempMin:       d.Temp.Min,
+			Humidity:      d.Humidity,
+			WindSpeed:     d.WindSpeed,
+			Condition:     cond.Description,
+			ConditionCode: cond.ID,
+			Icon:          cond.Icon,
+			PrecipChance:  int(d.Pop * 100),
+			UVIndex:       d.UVI,
+			Sunrise:       time.Unix(d.Sunrise, 0),
+			Sunset:        time.Unix(d.Sunset, 0),
+		})
+	}
+
+	for _, a := range resp.Alerts {
+		data.Alerts = append(data.Alerts, WeatherAlert{
+			SenderName:  a.SenderName,
+			Event:       a.Event,
+			Start:       time.Unix(a.Start, 0),
+			End:         time.Unix(a.End, 0),
+			Description: a.Description,
+			Tags:        a.Tags,
+		})
+	}
+
+	return data
+}
+
+// geocodeZip resolves a zip code with the OpenWeatherMap geocoding API
+func (c *Client) geocodeZip(ctx context.Context, zip, countryCode string) (*Location, error) {
+	place := zipPlace(zip, countryCode)
+	if loc, ok := c.geocodes.get(place); ok {
+		return loc, nil
+	}
+
+	geocodeURL := fmt.Sprintf("%s/zip?zip=%s,%s&appid=%s",
+		c.geocodingURL, url.QueryEscape(zip), countryCode, c.apiKey)
+
+	var geoResp ZipGeocodingResponse
+	if err := c.http.getJSON(ctx, geocodeURL, &geoResp); err != nil {
+		return nil, fmt.Errorf("error geocoding location: %w", err)
+	}
+
+	loc := Location{
+		Name:      geoResp.Name,
+		Country:   geoResp.Country,
+		Latitude:  geoResp.Lat,
+		Longitude: geoResp.Lon,
+	}
+	c.geocodes.put(place, loc)
+	return &loc, nil
+}
+
+// firstCondition returns the primary condition of an OpenWeatherMap entry
+func firstCondition(conditions []OpenWeatherMapCondition) OpenWeatherMapCondition {
+	if len(conditions) == 0 {
+		return OpenWeatherMapCondition{}
+	}
+	return conditions[0]
+}
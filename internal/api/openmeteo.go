@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// ProviderOpenMeteo is the registry name of the Open-Meteo provider
+	ProviderOpenMeteo = "openmeteo"
+
+	openMeteoURL = "https://api.open-meteo.com/v1"
+
+	// openMeteoHours is how many hourly forecasts are kept
+	openMeteoHours = 48
+	// openMeteoDays is the longest daily forecast Open-Meteo offers
+	openMeteoDays = 16
+)
+
+const (
+	openMeteoCurrentVars = "temperature_2m,apparent_temperature,relative_humidity_2m,pressure_msl," +
+		"wind_speed_10m,wind_direction_10m,cloud_cover,visibility,uv_index,weather_code,is_day"
+	openMeteoHourlyVars = "temperature_2m,apparent_temperature,relative_humidity_2m,wind_speed_10m," +
+		"weather_code,precipitation_probability"
+	openMeteoDailyVars = "weather_code,temperature_2m_max,temperature_2m_min,relative_humidity_2m_mean," +
+		"wind_speed_10m_max,precipitation_probability_max,uv_index_max,sunrise,sunset"
+)
+
+func init() {
+	RegisterProvider(ProviderOpenMeteo, func(opts ProviderOptions) (Provider, error) {
+		return NewOpenMeteoClient(opts), nil
+	})
+}
+
+// OpenMeteoClient is an Open-Meteo API client. Open-Meteo needs no API key.
+type OpenMeteoClient struct {
+	http     *fetcher
+	geocoder *openMeteoGeocoder
+	units    string
+	baseURL  string
+}
+
+// NewOpenMeteoClient creates a new Open-Meteo client
+func NewOpenMeteoClient(opts ProviderOptions) *OpenMeteoClient {
+	f := newFetcher(opts.UserAgent)
+	return &OpenMeteoClient{
+		http:     f,
+		geocoder: newOpenMeteoGeocoder(f, opts),
+		units:    opts.Units,
+		baseURL:  opts.endpoint(EndpointOpenMeteo, openMeteoURL),
+	}
+}
+
+// Name returns the registry name of the provider
+func (c *OpenMeteoClient) Name() string {
+	return ProviderOpenMeteo
+}
+
+// GetCurrent fetches current weather by coordinates
+func (c *OpenMeteoClient) GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	resp, err := c.fetch(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	data := c.parse(resp)
+	data.Hourly = nil
+	data.Daily = nil
+	return data, nil
+}
+
+// GetForecast fetches forecast data
+func (c *OpenMeteoClient) GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	resp, err := c.fetch(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	data := c.parse(resp)
+	return &WeatherData{
+		Hourly: data.Hourly,
+		Daily:  data.Daily,
+	}, nil
+}
+
+// Geocode converts city name to coordinates
+func (c *OpenMeteoClient) Geocode(ctx context.Context, city, state, country string) (float64, float64, error) {
+	loc, err := c.geocoder.geocodeCity(ctx, city, state, country)
+	if err != nil {
+		return 0, 0, err
+	}
+	return loc.Latitude, loc.Longitude, nil
+}
+
+// SearchLocations returns the places matching a city name
+func (c *OpenMeteoClient) SearchLocations(ctx context.Context, city, state, country string) ([]Location, error) {
+	return c.geocoder.searchCities(ctx, city, state, country)
+}
+
+// ReverseGeocode returns the place at coordinates
+func (c *OpenMeteoClient) ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error) {
+	return c.geocoder.reverseGeocode(ctx, lat, lon)
+}
+
+// GetWeatherByZip fetches weather data by zip code
+func (c *OpenMeteoClient) GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error) {
+	loc, err := c.geocoder.geocodeZip(ctx, zip, countryCode)
+	if err != nil {
+		return nil, err
+	}
+	return c.weatherAt(ctx, loc)
+}
+
+// GetWeatherByCity fetches weather data by city name
+func (c *OpenMeteoClient) GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error) {
+	loc, err := c.geocoder.geocodeCity(ctx, city, state, country)
+	if err != nil {
+		return nil, err
+	}
+	return c.weatherAt(ctx, loc)
+}
+
+// GetWeatherByCoords fetches weather data by coordinates. Current
+// conditions and forecast come from a single request.
+func (c *OpenMeteoClient) GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	resp, err := c.fetch(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	return c.parse(resp), nil
+}
+
+// weatherAt fetches weather for a geocoded location and keeps its name
+func (c *OpenMeteoClient) weatherAt(ctx context.Context, loc *Location) (*WeatherData, error) {
+	data, err := c.GetWeatherByCoords(ctx, loc.Latitude, loc.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	data.Location.Name = loc.Name
+	data.Location.State = loc.State
+	data.Location.Country = loc.Country
+	return data, nil
+}
+
+// fetch requests current conditions, hourly and daily forecasts at once
+func (c *OpenMeteoClient) fetch(ctx context.Context, lat, lon float64) (*OpenMeteoForecastResponse, error) {
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%f", lat))
+	params.Set("longitude", fmt.Sprintf("%f", lon))
+	params.Set("current", openMeteoCurrentVars)
+	params.Set("hourly", openMeteoHourlyVars)
+	params.Set("daily", openMeteoDailyVars)
+	params.Set("forecast_days", fmt.Sprintf("%d", openMeteoDays))
+	params.Set("timezone", "auto")
+	params.Set("timeformat", "unixtime")
+	if c.units == "imperial" {
+		params.Set("temperature_unit", "fahrenheit")
+		params.Set("wind_speed_unit", "mph")
+	} else {
+		params.Set("wind_speed_unit", "ms")
+	}
+
+	var resp OpenMeteoForecastResponse
+	if err := c.http.getJSON(ctx, c.baseURL+"/forecast?"+params.Encode(), &resp); err != nil {
+		return nil, fmt.Errorf("error fetching weather data: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// parse converts an Open-Meteo response to our WeatherData model
+func (c *OpenMeteoClient) parse(resp *OpenMeteoForecastResponse) *WeatherData {
+	cur := resp.Current
+	cond := wmoCondition(cur.WeatherCode)
+
+	data := &WeatherData{
+		Location: Location{
+			Latitude:  resp.Latitude,
+			Longitude: resp.Longitude,
+			Timezone:  resp.Timezone,
+		},
+		Current: CurrentWeather{
+			Temperature:   c.temp(cur.Temperature),
+			FeelsLike:     c.temp(cur.ApparentTemperature),
+			Humidity:      int(cur.Humidity),
+			Pressure:      int(cur.PressureMSL),
+			WindSpeed:     cur.WindSpeed,
+			WindDegree:    int(cur.WindDirection),
+			Visibility:    int(cur.Visibility),
+			CloudCover:    int(cur.CloudCover),
+			UVIndex:       cur.UVIndex,
+			Condition:     cond.description,
+			ConditionCode: cond.code,
+			Time:          time.Unix(cur.Time, 0),
+		},
+		Hourly: make([]HourlyForecast, 0, openMeteoHours),
+		Daily:  make([]DailyForecast, 0, len(resp.Daily.Time)),
+	}
+
+	// Hourly data starts at midnight local time, skip hours already past
+	hourly := resp.Hourly
+	start := time.Unix(cur.Time, 0).Truncate(time.Hour)
+	for i, ts := range hourly.Time {
+		t := time.Unix(ts, 0)
+		if t.Before(start) {
+			continue
+		}
+		if len(data.Hourly) >= openMeteoHours {
+			break
+		}
+
+		cond := wmoCondition(valueAt(hourly.WeatherCode, i))
+		data.Hourly = append(data.Hourly, HourlyForecast{
+			Time:          t,
+			Temperature:   c.temp(valueAt(hourly.Temperature, i)),
+			FeelsLike:     c.temp(valueAt(hourly.ApparentTemperature, i)),
+			Humidity:      int(valueAt(hourly.Humidity, i)),
+			WindSpeed:     valueAt(hourly.WindSpeed, i),
+			Condition:     cond.description,
+			ConditionCode: cond.code,
+			PrecipChance:  int(valueAt(hourly.PrecipitationProbability, i)),
+		})
+	}
+
+	daily := resp.Daily
+	for i, ts := range daily.Time {
+		cond := wmoCondition(valueAt(daily.WeatherCode, i))
+		day := DailyForecast{
+			Date:          time.Unix(ts, 0),
+			TempMax:       c.temp(valueAt(daily.TemperatureMax, i)),
+			TempMin:       c.temp(valueAt(daily.TemperatureMin, i)),
+			Humidity:      int(valueAt(daily.HumidityMean, i)),
+			WindSpeed:     valueAt(daily.WindSpeedMax, i),
+			Condition:     cond.description,
+			ConditionCode: cond.code,
+			PrecipChance:  int(valueAt(daily.PrecipitationProbabilityMax, i)),
+			UVIndex:       valueAt(daily.UVIndexMax, i),
+		}
+		if sunrise := valueAt(daily.Sunrise, i); sunrise != 0 {
+			day.Sunrise = time.Unix(sunrise, 0)
+		}
+		if sunset := valueAt(daily.Sunset, i); sunset != 0 {
+			day.Sunset = time.Unix(sunset, 0)
+		}
+		data.Daily = append(data.Daily, day)
+	}
+
+	// Today's sunrise and sunset belong to current conditions as well
+	if len(data.Daily) > 0 {
+		data.Current.Sunrise = data.Daily[0].Sunrise
+		data.Current.Sunset = data.Daily[0].Sunset
+	}
+
+	return data
+}
+
+// temp converts a temperature from the requested unit to the configured
+// one. Open-Meteo has no Kelvin option, so standard units are converted here.
+func (c *OpenMeteoClient) temp(t float64) float64 {
+	if c.units == "standard" {
+		return celsiusToKelvin(t)
+	}
+	return t
+}
+
+// valueAt returns values[i], or the zero value when the series is short
+func valueAt[T any](values []T, i int) T {
+	var zero T
+	if i < 0 || i >= len(values) {
+		return zero
+	}
+	return values[i]
+}
@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/api"
+)
+
+// fixtureServer serves a recorded response from testdata for every
+// request and hands each request's query to check
+func fixtureServer(t *testing.T, name string, check func(url.Values)) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r.URL.Query())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenMeteoClient(t *testing.T) {
+	tests := []struct {
+		units       string
+		tempUnit    string
+		windUnit    string
+		temperature float64
+	}{
+		{"imperial", "fahrenheit", "mph", 68.2},
+		{"metric", "", "ms", 68.2},
+		// Open-Meteo has no Kelvin, so standard asks for °C and converts
+		{"standard", "", "ms", 68.2 + 273.15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.units, func(t *testing.T) {
+			server := fixtureServer(t, "openmeteo_forecast.json", func(q url.Values) {
+				if q.Get("temperature_unit") != tt.tempUnit || q.Get("wind_speed_unit") != tt.windUnit {
+					t.Errorf("Expected units %q/%q, got %q/%q", tt.tempUnit, tt.windUnit,
+						q.Get("temperature_unit"), q.Get("wind_speed_unit"))
+				}
+				if q.Get("timeformat") != "unixtime" {
+					t.Errorf("Expected unixtime timestamps, got %q", q.Get("timeformat"))
+				}
+			})
+
+			client := api.NewOpenMeteoClient(api.ProviderOptions{
+				Units:     tt.units,
+				Endpoints: map[string]string{api.EndpointOpenMeteo: server.URL},
+			})
+			data, err := client.GetWeatherByCoords(context.Background(), 40.7128, -74.006)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			cur := data.Current
+			if diff := cur.Temperature - tt.temperature; diff < -0.001 || diff > 0.001 {
+				t.Errorf("Expected temperature %.2f, got %.2f", tt.temperature, cur.Temperature)
+			}
+			if cur.Humidity != 71 || cur.Pressure != 1012 || cur.WindDegree != 200 || cur.CloudCover != 88 {
+				t.Errorf("Unexpected current conditions %+v", cur)
+			}
+			// WMO 61 is light rain
+			if cur.ConditionCode != 500 || cur.Condition != "light rain" {
+				t.Errorf("Expected light rain (500), got %q (%d)", cur.Condition, cur.ConditionCode)
+			}
+			if data.Location.Timezone != "America/New_York" {
+				t.Errorf("Expected timezone America/New_York, got %q", data.Location.Timezone)
+			}
+
+			// The series starts at midnight; hours before the current one
+			// are dropped
+			if len(data.Hourly) != 4 {
+				t.Fatalf("Expected 4 hourly forecasts from the current hour, got %d", len(data.Hourly))
+			}
+			if want := time.Unix(1748779200, 0); !data.Hourly[0].Time.Equal(want) {
+				t.Errorf("Expected first hour %v, got %v", want, data.Hourly[0].Time)
+			}
+			if data.Hourly[1].ConditionCode != 520 || data.Hourly[1].PrecipChance != 48 {
+				t.Errorf("Expected light rain showers at 48%%, got %+v", data.Hourly[1])
+			}
+
+			if len(data.Daily) != 3 {
+				t.Fatalf("Expected 3 daily forecasts, got %d", len(data.Daily))
+			}
+			if day := data.Daily[1]; day.ConditionCode != 211 || day.PrecipChance != 80 || day.UVIndex != 7.2 {
+				t.Errorf("Expected a thunderstorm day at 80%%, got %+v", day)
+			}
+			if cur.Sunrise.IsZero() || !cur.Sunrise.Equal(data.Daily[0].Sunrise) || !cur.Sunset.Equal(data.Daily[0].Sunset) {
+				t.Errorf("Expected today's sunrise and sunset in current conditions, got %v and %v", cur.Sunrise, cur.Sunset)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sources of the current position, selected with the here_source setting
+const (
+	PositionIP   = "ip"
+	PositionGPSD = "gpsd"
+)
+
+const (
+	// DefaultIPLocateURL is the IP geolocation service asked by default
+	DefaultIPLocateURL = "https://ipapi.co/json/"
+
+	// DefaultGPSDAddress is where gpsd listens by default
+	DefaultGPSDAddress = "localhost:2947"
+)
+
+// Position is where the machine running weatherornot is
+type Position struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Source    string  `json:"source"` // PositionIP or PositionGPSD
+}
+
+// Locator finds the current position
+type Locator interface {
+	Locate(ctx context.Context) (*Position, error)
+}
+
+// NewLocator builds the locator for a position source. An empty ipURL or
+// gpsdAddress uses the default.
+func NewLocator(source, ipURL, gpsdAddress, userAgent string) (Locator, error) {
+	switch strings.ToLower(strings.TrimSpace(source)) {
+	case PositionIP, "":
+		return NewIPLocator(ipURL, userAgent), nil
+	case PositionGPSD:
+		return NewGPSDLocator(gpsdAddress), nil
+	}
+	return nil, fmt.Errorf("unknown position source %q (available: %s, %s)", source, PositionIP, PositionGPSD)
+}
+
+// IPLocator finds the position of the machine's public IP address with an
+// IP geolocation service
+type IPLocator struct {
+	url   string
+	fetch *fetcher
+}
+
+// NewIPLocator creates a locator asking the service at url, or
+// DefaultIPLocateURL if url is empty
+func NewIPLocator(url, userAgent string) *IPLocator {
+	if strings.TrimSpace(url) == "" {
+		url = DefaultIPLocateURL
+	}
+	return &IPLocator{url: strings.TrimSpace(url), fetch: newFetcher(userAgent)}
+}
+
+// ipLocation is the response of an IP geolocation service. Services
+// disagree on field names, so the common ones are all accepted: ipapi.co
+// and most self-hosted services use latitude and longitude, ip-api.com
+// uses lat and lon, and ipinfo.io gives both in loc.
+type ipLocation struct {
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Lat       *float64 `json:"lat"`
+	Lon       *float64 `json:"lon"`
+	Loc       string   `json:"loc"`
+
+	// Failures are reported in the body by ipapi.co (error, reason) and
+	// ip-api.com (status, message)
+	Error   bool   `json:"error"`
+	Reason  string `json:"reason"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Locate asks the IP geolocation service where the machine is
+func (l *IPLocator) Locate(ctx context.Context) (*Position, error) {
+	var resp ipLocation
+	if err := l.fetch.getJSON(ctx, l.url, &resp); err != nil {
+		return nil, fmt.Errorf("IP geolocation failed: %w", err)
+	}
+
+	switch {
+	case resp.Error:
+		return nil, fmt.Errorf("IP geolocation failed: %s", resp.Reason)
+	case resp.Status == "fail":
+		return nil, fmt.Errorf("IP geolocation failed: %s", resp.Message)
+	}
+
+	lat, lon, ok := resp.coords()
+	if !ok {
+		return nil, fmt.Errorf("IP geolocation service at %s returned no coordinates", l.url)
+	}
+	return &Position{Latitude: lat, Longitude: lon, Source: PositionIP}, nil
+}
+
+// coords returns the coordinates in whichever fields the service used
+func (r ipLocation) coords() (float64, float64, bool) {
+	switch {
+	case r.Latitude != nil && r.Longitude != nil:
+		return *r.Latitude, *r.Longitude, true
+	case r.Lat != nil && r.Lon != nil:
+		return *r.Lat, *r.Lon, true
+	}
+
+	latText, lonText, ok := strings.Cut(r.Loc, ",")
+	if !ok {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonText), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
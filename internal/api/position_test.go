@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIPLocator(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		lat     float64
+		lon     float64
+		wantErr string
+	}{
+		{"latitude and longitude", `{"ip":"8.8.8.8","latitude":37.751,"longitude":-97.822}`, 37.751, -97.822, ""},
+		{"lat and lon", `{"status":"success","lat":51.5074,"lon":-0.1278}`, 51.5074, -0.1278, ""},
+		{"loc", `{"ip":"1.1.1.1","loc":"-33.8688,151.2093"}`, -33.8688, 151.2093, ""},
+		{"error reason", `{"error":true,"reason":"Reserved IP Address"}`, 0, 0, "Reserved IP Address"},
+		{"failed status", `{"status":"fail","message":"private range"}`, 0, 0, "private range"},
+		{"no coordinates", `{"ip":"8.8.8.8"}`, 0, 0, "no coordinates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			pos, err := NewIPLocator(server.URL, "").Locate(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Locate() error = %v", err)
+			}
+			if pos.Latitude != tt.lat || pos.Longitude != tt.lon || pos.Source != PositionIP {
+				t.Errorf("Expected %v,%v from ip, got %+v", tt.lat, tt.lon, pos)
+			}
+		})
+	}
+}
+
+// fakeGPSD serves one gpsd connection, answering the WATCH command with
+// the given report lines
+func fakeGPSD(t *testing.T, reports ...string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintln(conn, `{"class":"VERSION","release":"3.25","proto_major":3,"proto_minor":15}`)
+		if line, err := bufio.NewReader(conn).ReadString('\n'); err != nil || !strings.HasPrefix(line, "?WATCH=") {
+			return
+		}
+		for _, r := range reports {
+			fmt.Fprintln(conn, r)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestGPSDLocator(t *testing.T) {
+	addr := fakeGPSD(t,
+		`{"class":"DEVICES","devices":[{"class":"DEVICE","path":"/dev/ttyUSB0"}]}`,
+		`{"class":"WATCH","enable":true,"json":true}`,
+		`{"class":"TPV","device":"/dev/ttyUSB0","mode":1}`,
+		`{"class":"SKY","device":"/dev/ttyUSB0","satellites":[]}`,
+		`{"class":"TPV","device":"/dev/ttyUSB0","mode":3,"lat":47.6062,"lon":-122.3321,"alt":56.0}`,
+	)
+
+	pos, err := NewGPSDLocator(addr).Locate(context.Background())
+	if err != nil {
+		t.Fatalf("Locate() error = %v", err)
+	}
+	if pos.Latitude != 47.6062 || pos.Longitude != -122.3321 || pos.Source != PositionGPSD {
+		t.Errorf("Expected 47.6062,-122.3321 from gpsd, got %+v", pos)
+	}
+}
+
+func TestGPSDLocatorWithoutFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		reports []string
+		wantErr string
+	}{
+		{"no receiver", []string{`{"class":"DEVICES","devices":[]}`}, "no GPS receiver"},
+		{"no fix", []string{`{"class":"TPV","mode":1}`}, "without a position fix"},
+		{"error", []string{`{"class":"ERROR","message":"Unrecognized request"}`}, "Unrecognized request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := fakeGPSD(t, tt.reports...)
+			_, err := NewGPSDLocator(addr).Locate(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
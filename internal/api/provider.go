@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Provider is a weather data backend. Each backend registers itself under
+// a name that is selected with the provider setting in the config file.
+type Provider interface {
+	// Name returns the registry name of the provider
+	Name() string
+
+	// GetCurrent fetches current conditions and location details
+	GetCurrent(ctx context.Context, lat, lon float64) (*WeatherData, error)
+
+	// GetForecast fetches hourly and daily forecasts
+	GetForecast(ctx context.Context, lat, lon float64) (*WeatherData, error)
+
+	// Geocode converts a city name to coordinates
+	Geocode(ctx context.Context, city, state, country string) (float64, float64, error)
+
+	// SearchLocations returns the places matching a city name, best match
+	// first, so ambiguous names can be told apart
+	SearchLocations(ctx context.Context, city, state, country string) ([]Location, error)
+
+	// ReverseGeocode returns the place at coordinates
+	ReverseGeocode(ctx context.Context, lat, lon float64) (*Location, error)
+
+	// GetWeatherByZip fetches current conditions and forecast by zip code
+	GetWeatherByZip(ctx context.Context, zip, countryCode string) (*WeatherData, error)
+
+	// GetWeatherByCity fetches current conditions and forecast by city name
+	GetWeatherByCity(ctx context.Context, city, state, country string) (*WeatherData, error)
+
+	// GetWeatherByCoords fetches current conditions and forecast by coordinates
+	GetWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherData, error)
+}
+
+// ProviderOptions holds the settings used to build a provider
+type ProviderOptions struct {
+	APIKey string
+	Units  string
+
+	// UserAgent is sent with every request; defaults to DefaultUserAgent
+	UserAgent string
+
+	// OneCall switches OpenWeatherMap to the One Call 3.0 API
+	OneCall bool
+
+	// Members are the providers combined by the ensemble provider
+	Members []string
+
+	// CacheDir holds provider response caches; empty keeps nothing on disk
+	CacheDir string
+
+	// GeocodeTTL is how long cached geocoding results are reused; zero
+	// uses DefaultCacheTTL.Geocode
+	GeocodeTTL time.Duration
+
+	// Refresh ignores cached responses but still stores fresh ones
+	Refresh bool
+
+	// Endpoints overrides API base URLs, keyed by the Endpoint constants,
+	// to use a mirror, proxy or test server
+	Endpoints map[string]string
+}
+
+// Keys of ProviderOptions.Endpoints
+const (
+	EndpointOpenWeatherMap        = "openweathermap"
+	EndpointOpenWeatherMapOneCall = "openweathermap_onecall"
+	EndpointOpenWeatherMapGeo     = "openweathermap_geo"
+	EndpointOpenMeteo             = "openmeteo"
+	EndpointOpenMeteoGeo          = "openmeteo_geo"
+	EndpointMETNorway             = "metno"
+	EndpointNWS                   = "nws"
+	EndpointNominatim             = "nominatim"
+)
+
+// EndpointNames returns the keys accepted in ProviderOptions.Endpoints
+func EndpointNames() []string {
+	return []string{
+		EndpointOpenWeatherMap,
+		EndpointOpenWeatherMapOneCall,
+		EndpointOpenWeatherMapGeo,
+		EndpointOpenMeteo,
+		EndpointOpenMeteoGeo,
+		EndpointMETNorway,
+		EndpointNWS,
+		EndpointNominatim,
+	}
+}
+
+// endpoint returns the configured base URL for key, or def if none is set
+func (o ProviderOptions) endpoint(key, def string) string {
+	if u := strings.TrimSpace(o.Endpoints[key]); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return def
+}
+
+// ProviderFactory builds a provider from its options
+type ProviderFactory func(opts ProviderOptions) (Provider, error)
+
+var providers = make(map[string]ProviderFactory)
+
+// RegisterProvider makes a provider available under the given name.
+// Names are case-insensitive.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providers[strings.ToLower(name)] = factory
+}
+
+// NewProvider builds the provider registered under name
+func NewProvider(name string, opts ProviderOptions) (Provider, error) {
+	factory, ok := providers[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(ProviderNames(), ", "))
+	}
+	return factory(opts)
+}
+
+// ProviderNames returns the names of all registered providers
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsProvider reports whether a provider is registered under name
+func IsProvider(name string) bool {
+	_, ok := providers[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// combine merges separately fetched current conditions and forecast
+func combine(current, forecast *WeatherData) *WeatherData {
+	return &WeatherData{
+		Current:  current.Current,
+		Location: current.Location,
+		Hourly:   forecast.Hourly,
+		Daily:    forecast.Daily,
+	}
+}
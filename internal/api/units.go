@@ -0,0 +1,26 @@
+package api
+
+// celsiusToKelvin converts a Celsius temperature to Kelvin
+func celsiusToKelvin(c float64) float64 {
+	return c + 273.15
+}
+
+// celsiusToFahrenheit converts a Celsius temperature to Fahrenheit
+func celsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// kmhToMs converts a speed from km/h to m/s
+func kmhToMs(kmh float64) float64 {
+	return kmh / 3.6
+}
+
+// kmhToMph converts a speed from km/h to mph
+func kmhToMph(kmh float64) float64 {
+	return kmh / 1.609344
+}
+
+// msToMph converts a speed from m/s to mph
+func msToMph(ms float64) float64 {
+	return ms * 2.236936
+}
@@ -0,0 +1,137 @@
+// Package apitest provides a fake OpenWeatherMap API for integration tests
+// and offline demos. It serves canned /weather, /forecast, /geo/1.0/direct,
+// /geo/1.0/zip and /geo/1.0/reverse responses from embedded fixtures.
+package apitest
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/james-see/weatherornot/internal/api"
+)
+
+const (
+	// UnknownCity is a city name the fake API reports as not found
+	UnknownCity = "Nowhere"
+
+	// AmbiguousCity is a city name the fake geocoding API finds in several
+	// states
+	AmbiguousCity = "Springfield"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Handler serves the fake OpenWeatherMap API. A non-empty apiKey is the
+// only appid it accepts; any other key gets a 401 like the real API.
+type Handler struct {
+	apiKey string
+	mux    *http.ServeMux
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// NewHandler creates a fake OpenWeatherMap API handler
+func NewHandler(apiKey string) *Handler {
+	h := &Handler{apiKey: apiKey, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/weather", h.fixture("weather.json"))
+	h.mux.HandleFunc("/forecast", h.fixture("forecast.json"))
+	h.mux.HandleFunc("/geo/1.0/direct", h.fixture("direct.json"))
+	h.mux.HandleFunc("/geo/1.0/zip", h.fixture("zip.json"))
+	h.mux.HandleFunc("/geo/1.0/reverse", h.fixture("direct.json"))
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.requests = append(h.requests, r.URL.Path)
+	h.mu.Unlock()
+
+	if h.apiKey != "" && r.URL.Query().Get("appid") != h.apiKey {
+		writeError(w, http.StatusUnauthorized, "Invalid API key. Please see https://openweathermap.org/faq#error401 for more info.")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// Requests returns the paths requested so far, in order
+func (h *Handler) Requests() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.requests...)
+}
+
+// fixture serves the named fixture file
+func (h *Handler) fixture(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		city, _, _ := strings.Cut(r.URL.Query().Get("q"), ",")
+		if strings.EqualFold(city, UnknownCity) {
+			if r.URL.Path == "/geo/1.0/direct" {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte("[]"))
+				return
+			}
+			writeError(w, http.StatusNotFound, "city not found")
+			return
+		}
+
+		file := name
+		if r.URL.Path == "/geo/1.0/direct" && strings.EqualFold(city, AmbiguousCity) {
+			file = "springfield.json"
+		}
+
+		body, err := fixtures.ReadFile("fixtures/" + file)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+// writeError writes an error body in the OpenWeatherMap format
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"cod":     status,
+		"message": message,
+	})
+}
+
+// Server is a fake OpenWeatherMap API listening on a local port
+type Server struct {
+	*httptest.Server
+	*Handler
+}
+
+// NewServer starts a fake OpenWeatherMap API. Call Close when done.
+func NewServer(apiKey string) *Server {
+	h := NewHandler(apiKey)
+	return &Server{
+		Server:  httptest.NewServer(h),
+		Handler: h,
+	}
+}
+
+// Endpoints returns the provider endpoints that point OpenWeatherMap at
+// the server, for use in api.ProviderOptions
+func (s *Server) Endpoints() map[string]string {
+	return Endpoints(s.URL)
+}
+
+// Endpoints returns the provider endpoints that point OpenWeatherMap at a
+// fake API served at baseURL
+func Endpoints(baseURL string) map[string]string {
+	return map[string]string{
+		api.EndpointOpenWeatherMap:    baseURL,
+		api.EndpointOpenWeatherMapGeo: baseURL + "/geo/1.0",
+	}
+}
@@ -0,0 +1,112 @@
+// Package cache stores JSON values on disk with the time they were stored,
+// so callers can decide how old a value may be before it is refetched.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dir returns the weatherornot cache directory, honouring XDG_CACHE_HOME
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "weatherornot"), nil
+}
+
+// Cache is a directory of cached JSON values
+type Cache struct {
+	dir string
+}
+
+// entry is a cached value as stored on disk
+type entry struct {
+	Key      string          `json:"key"`
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// New creates a cache in dir. The directory is created on first write.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Get decodes the value stored under key into v if it is younger than
+// maxAge, and reports whether it did
+func (c *Cache) Get(key string, maxAge time.Duration, v interface{}) bool {
+	storedAt, ok := c.Load(key, v)
+	return ok && time.Since(storedAt) < maxAge
+}
+
+// Load decodes the value stored under key into v regardless of its age,
+// and returns the time it was stored
+func (c *Cache) Load(key string, v interface{}) (time.Time, bool) {
+	raw, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil || e.Key != key {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		return time.Time{}, false
+	}
+	return e.StoredAt, true
+}
+
+// Put stores v under key
+func (c *Cache) Put(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding cache entry: %w", err)
+	}
+	raw, err := json.Marshal(entry{Key: key, StoredAt: time.Now(), Value: value})
+	if err != nil {
+		return fmt.Errorf("error encoding cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent runs never read a
+	// half-written entry
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error writing cache entry: %w", err)
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error writing cache entry: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error writing cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cached value
+func (c *Cache) Clear() error {
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("error clearing cache: %w", err)
+	}
+	return nil
+}
+
+// path returns the file holding key
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+".json")
+}
@@ -33,6 +33,19 @@ func Load() (*Config, error) {
 	viper.SetDefault("display_mode", cfg.DisplayMode)
 	viper.SetDefault("show_colors", cfg.ShowColors)
 	viper.SetDefault("favorites", cfg.Favorites)
+	viper.SetDefault("user_agent", cfg.UserAgent)
+	viper.SetDefault("one_call", cfg.OneCall)
+	viper.SetDefault("ensemble", cfg.Ensemble)
+	viper.SetDefault("fallback_providers", cfg.FallbackProviders)
+	viper.SetDefault("endpoints", cfg.Endpoints)
+	viper.SetDefault("cache_current_ttl", cfg.CacheCurrentTTL)
+	viper.SetDefault("cache_forecast_ttl", cfg.CacheForecastTTL)
+	viper.SetDefault("cache_geocode_ttl", cfg.CacheGeocodeTTL)
+	viper.SetDefault("gazetteer", cfg.Gazetteer)
+	viper.SetDefault("here_source", cfg.HereSource)
+	viper.SetDefault("here_ip_url", cfg.HereIPURL)
+	viper.SetDefault("here_gpsd_address", cfg.HereGPSDAddress)
+	viper.SetDefault("here_ttl", cfg.HereTTL)
 
 	// Try to read config
 	if err := viper.ReadInConfig(); err != nil {
@@ -71,13 +84,7 @@ func Create(cfg *Config) error {
 	configPath := filepath.Join(configDir, configFileName+".toml")
 	
 	viper.SetConfigFile(configPath)
-	viper.Set("api_key", cfg.APIKey)
-	viper.Set("provider", cfg.Provider)
-	viper.Set("default_location", cfg.DefaultLocation)
-	viper.Set("units", cfg.Units)
-	viper.Set("display_mode", cfg.DisplayMode)
-	viper.Set("show_colors", cfg.ShowColors)
-	viper.Set("favorites", cfg.Favorites)
+	setValues(cfg)
 
 	if err := viper.WriteConfig(); err != nil {
 		return fmt.Errorf("error writing config file: %w", err)
@@ -88,6 +95,17 @@ func Create(cfg *Config) error {
 
 // Save saves the current configuration to the config file
 func Save(cfg *Config) error {
+	setValues(cfg)
+
+	if err := viper.WriteConfig(); err != nil {
+		return fmt.Errorf("error saving config file: %w", err)
+	}
+
+	return nil
+}
+
+// setValues copies every config field into viper for writing
+func setValues(cfg *Config) {
 	viper.Set("api_key", cfg.APIKey)
 	viper.Set("provider", cfg.Provider)
 	viper.Set("default_location", cfg.DefaultLocation)
@@ -95,12 +113,19 @@ func Save(cfg *Config) error {
 	viper.Set("display_mode", cfg.DisplayMode)
 	viper.Set("show_colors", cfg.ShowColors)
 	viper.Set("favorites", cfg.Favorites)
-
-	if err := viper.WriteConfig(); err != nil {
-		return fmt.Errorf("error saving config file: %w", err)
-	}
-
-	return nil
+	viper.Set("user_agent", cfg.UserAgent)
+	viper.Set("one_call", cfg.OneCall)
+	viper.Set("ensemble", cfg.Ensemble)
+	viper.Set("fallback_providers", cfg.FallbackProviders)
+	viper.Set("endpoints", cfg.Endpoints)
+	viper.Set("cache_current_ttl", cfg.CacheCurrentTTL)
+	viper.Set("cache_forecast_ttl", cfg.CacheForecastTTL)
+	viper.Set("cache_geocode_ttl", cfg.CacheGeocodeTTL)
+	viper.Set("gazetteer", cfg.Gazetteer)
+	viper.Set("here_source", cfg.HereSource)
+	viper.Set("here_ip_url", cfg.HereIPURL)
+	viper.Set("here_gpsd_address", cfg.HereGPSDAddress)
+	viper.Set("here_ttl", cfg.HereTTL)
 }
 
 // GetConfigPath returns the path to the config file
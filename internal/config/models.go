@@ -9,6 +9,19 @@ type Config struct {
 	DisplayMode   string            `mapstructure:"display_mode"`
 	ShowColors    bool              `mapstructure:"show_colors"`
 	Favorites     map[string]string `mapstructure:"favorites"`
+	UserAgent     string            `mapstructure:"user_agent"`
+	OneCall       bool              `mapstructure:"one_call"`
+	Ensemble      []string          `mapstructure:"ensemble"`
+	FallbackProviders []string      `mapstructure:"fallback_providers"`
+	Endpoints     map[string]string `mapstructure:"endpoints"`
+	CacheCurrentTTL  string         `mapstructure:"cache_current_ttl"`
+	CacheForecastTTL string         `mapstructure:"cache_forecast_ttl"`
+	CacheGeocodeTTL  string         `mapstructure:"cache_geocode_ttl"`
+	Gazetteer     string            `mapstructure:"gazetteer"`
+	HereSource    string            `mapstructure:"here_source"`
+	HereIPURL     string            `mapstructure:"here_ip_url"`
+	HereGPSDAddress string          `mapstructure:"here_gpsd_address"`
+	HereTTL       string            `mapstructure:"here_ttl"`
 }
 
 // DefaultConfig returns a new Config with default values
@@ -21,6 +34,17 @@ func DefaultConfig() *Config {
 		DisplayMode:     "widget",
 		ShowColors:      true,
 		Favorites:       make(map[string]string),
+		Ensemble:        []string{},
+		FallbackProviders: []string{},
+		Endpoints:       make(map[string]string),
+		CacheCurrentTTL:  "10m",
+		CacheForecastTTL: "1h",
+		CacheGeocodeTTL:  "720h",
+		Gazetteer:       "fallback",
+		HereSource:      "ip",
+		HereIPURL:       "https://ipapi.co/json/",
+		HereGPSDAddress: "localhost:2947",
+		HereTTL:         "30m",
 	}
 }
 
@@ -0,0 +1,34 @@
+package display
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/james-see/weatherornot/internal/api"
+)
+
+// staleNotice describes how old stale data is, e.g. "as of 2h ago", or
+// returns "" for fresh data
+func staleNotice(data *api.WeatherData) string {
+	if !data.Stale {
+		return ""
+	}
+	if data.FetchedAt.IsZero() {
+		return "cached data"
+	}
+	return "as of " + FormatAge(time.Since(data.FetchedAt))
+}
+
+// FormatAge formats a duration in its largest whole unit
+func FormatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
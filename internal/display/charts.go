@@ -35,8 +35,21 @@ func (d *ChartDisplay) RenderHourlyTempChart(data *api.WeatherData, hours int) s
 
 	// Extract temperatures
 	temps := make([]float64, maxHours)
+	lows := make([]float64, maxHours)
+	highs := make([]float64, maxHours)
+	hasSpread := false
 	for i := 0; i < maxHours; i++ {
-		temps[i] = data.Hourly[i].Temperature
+		hour := data.Hourly[i]
+		temps[i] = hour.Temperature
+		lows[i], highs[i] = hour.Temperature, hour.Temperature
+		if hour.Sources > 1 {
+			lows[i], highs[i] = hour.TempSpread.Min, hour.TempSpread.Max
+			hasSpread = true
+		}
+	}
+
+	if hasSpread {
+		return d.renderSpreadChart(temps, lows, highs)
 	}
 
 	// Create chart
@@ -49,6 +62,33 @@ func (d *ChartDisplay) RenderHourlyTempChart(data *api.WeatherData, hours int) s
 	return d.addTempLabels(graph, temps)
 }
 
+// renderSpreadChart renders the median temperature of an ensemble forecast
+// between the lowest and highest provider values as an uncertainty band
+func (d *ChartDisplay) renderSpreadChart(temps, lows, highs []float64) string {
+	options := []asciigraph.Option{
+		asciigraph.Height(10),
+		asciigraph.Width(60),
+		asciigraph.Caption(fmt.Sprintf("Temperature Trend (Next %d Hours, provider range)", len(temps))),
+		asciigraph.SeriesLegends("low", "median", "high"),
+	}
+	if d.useColors {
+		options = append(options, asciigraph.SeriesColors(asciigraph.Blue, asciigraph.Default, asciigraph.Red))
+	}
+
+	graph := asciigraph.PlotMany([][]float64{lows, temps, highs}, options...)
+
+	// Report the widest disagreement between providers
+	var widest float64
+	for i := range temps {
+		if w := highs[i] - lows[i]; w > widest {
+			widest = w
+		}
+	}
+
+	return d.addTempLabels(graph, append(append([]float64{}, lows...), highs...)) +
+		fmt.Sprintf("Largest provider spread: %.1f%s\n", widest, d.getTempUnit())
+}
+
 // RenderDailyTempChart renders an ASCII chart of daily temperatures
 func (d *ChartDisplay) RenderDailyTempChart(data *api.WeatherData) string {
 	if len(data.Daily) == 0 {
@@ -158,29 +198,34 @@ func (d *ChartDisplay) getTempUnit() string {
 
 // RenderCompactTempGraph renders a compact inline temperature trend
 func (d *ChartDisplay) RenderCompactTempGraph(temps []float64) string {
-	if len(temps) == 0 {
+	return sparkline(temps)
+}
+
+// sparkline renders values as an inline graph of block characters
+func sparkline(values []float64) string {
+	if len(values) == 0 {
 		return ""
 	}
 
 	// Simple sparkline-style graph using block characters
-	min, max := temps[0], temps[0]
-	for _, t := range temps {
-		if t < min {
-			min = t
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
 		}
-		if t > max {
-			max = t
+		if v > max {
+			max = v
 		}
 	}
 
 	var output strings.Builder
 	blocks := []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
 
-	for _, temp := range temps {
+	for _, v := range values {
 		// Normalize to 0-7 range
 		normalized := 0
 		if max > min {
-			normalized = int(((temp - min) / (max - min)) * 7)
+			normalized = int(((v - min) / (max - min)) * 7)
 		}
 		if normalized < 0 {
 			normalized = 0
@@ -194,3 +239,35 @@ func (d *ChartDisplay) RenderCompactTempGraph(temps []float64) string {
 	return output.String()
 }
 
+// precipitationSummary describes minutely precipitation for the next hour,
+// e.g. "rain starting in 12 min", followed by a sparkline of intensity
+func precipitationSummary(minutely []api.MinutelyForecast) string {
+	if len(minutely) == 0 {
+		return ""
+	}
+
+	values := make([]float64, len(minutely))
+	for i, m := range minutely {
+		values[i] = m.Precipitation
+	}
+
+	// Scale against a fixed floor so light drizzle doesn't fill the graph
+	graph := sparkline(append(values, 0, 2))
+	graph = string([]rune(graph)[:len(values)])
+
+	raining := values[0] > 0
+	for i, v := range values {
+		if (v > 0) != raining {
+			minutes := int(minutely[i].Time.Sub(minutely[0].Time).Minutes())
+			if raining {
+				return fmt.Sprintf("precipitation ending in %d min  %s", minutes, graph)
+			}
+			return fmt.Sprintf("precipitation starting in %d min  %s", minutes, graph)
+		}
+	}
+
+	if raining {
+		return "precipitation for the next hour  " + graph
+	}
+	return "no precipitation for the next hour"
+}
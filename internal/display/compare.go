@@ -0,0 +1,128 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guptarohit/asciigraph"
+	"github.com/james-see/weatherornot/internal/api"
+)
+
+// comparisonColumns is how many locations are shown side by side before
+// starting a new row
+const comparisonColumns = 3
+
+// comparisonColors pairs the box color of each compared location with its
+// series color in the comparison chart
+var comparisonColors = []struct {
+	box    lipgloss.Color
+	series asciigraph.AnsiColor
+}{
+	{lipgloss.Color("12"), asciigraph.Blue},
+	{lipgloss.Color("11"), asciigraph.Yellow},
+	{lipgloss.Color("13"), asciigraph.Magenta},
+	{lipgloss.Color("10"), asciigraph.Green},
+	{lipgloss.Color("14"), asciigraph.Cyan},
+	{lipgloss.Color("9"), asciigraph.Red},
+}
+
+// RenderComparison renders the current conditions and next hours of
+// several locations in aligned columns. labels name each location, e.g.
+// the favorite it came from.
+func (d *WidgetDisplay) RenderComparison(locations []*api.WeatherData, labels []string, hours int) string {
+	var rows []string
+	for start := 0; start < len(locations); start += comparisonColumns {
+		end := start + comparisonColumns
+		if end > len(locations) {
+			end = len(locations)
+		}
+
+		var columns []string
+		for i := start; i < end; i++ {
+			color := comparisonColors[i%len(comparisonColors)].box
+			columns = append(columns, d.renderComparisonColumn(locations[i], labels[i], hours, color))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// renderComparisonColumn renders one location of a comparison
+func (d *WidgetDisplay) renderComparisonColumn(data *api.WeatherData, label string, hours int, color lipgloss.Color) string {
+	var content strings.Builder
+
+	tempUnit := d.getTempUnit()
+	windUnit := d.getWindUnit()
+
+	content.WriteString(locationName(data.Location) + "\n")
+	if notice := staleNotice(data); notice != "" {
+		content.WriteString("⚠ " + notice + "\n")
+	}
+
+	isNight := data.Current.Time.After(data.Current.Sunset) || data.Current.Time.Before(data.Current.Sunrise)
+	content.WriteString(fmt.Sprintf("\n%s  %s\n\n", GetSimpleIcon(data.Current.ConditionCode, isNight), strings.Title(data.Current.Condition)))
+	content.WriteString(fmt.Sprintf("Temp:      %.1f%s\n", data.Current.Temperature, tempUnit))
+	content.WriteString(fmt.Sprintf("Feels:     %.1f%s\n", data.Current.FeelsLike, tempUnit))
+	content.WriteString(fmt.Sprintf("Humidity:  %d%%\n", data.Current.Humidity))
+	content.WriteString(fmt.Sprintf("Wind:      %.1f %s", data.Current.WindSpeed, windUnit))
+
+	if len(data.Hourly) > 0 {
+		content.WriteString("\n")
+	}
+	for i, hour := range data.Hourly {
+		if i >= hours {
+			break
+		}
+		content.WriteString(fmt.Sprintf("\n%s  %s  %.1f%s",
+			hour.Time.Format("15:04"), GetSimpleIcon(hour.ConditionCode, false), hour.Temperature, tempUnit))
+	}
+
+	column := lipgloss.NewStyle().Width(28).Render(content.String())
+	return lipgloss.NewStyle().MarginRight(1).Render(d.renderBox(label, column, color))
+}
+
+// RenderComparisonChart plots the hourly temperatures of several locations
+// on one chart, one series per location
+func (d *ChartDisplay) RenderComparisonChart(locations []*api.WeatherData, labels []string, hours int) string {
+	var series [][]float64
+	var legends []string
+	var colors []asciigraph.AnsiColor
+	var all []float64
+
+	for i, data := range locations {
+		n := hours
+		if len(data.Hourly) < n {
+			n = len(data.Hourly)
+		}
+		if n == 0 {
+			continue
+		}
+
+		temps := make([]float64, n)
+		for j := 0; j < n; j++ {
+			temps[j] = data.Hourly[j].Temperature
+		}
+		series = append(series, temps)
+		legends = append(legends, labels[i])
+		colors = append(colors, comparisonColors[i%len(comparisonColors)].series)
+		all = append(all, temps...)
+	}
+
+	if len(series) == 0 {
+		return ""
+	}
+
+	options := []asciigraph.Option{
+		asciigraph.Height(10),
+		asciigraph.Width(60),
+		asciigraph.Caption(fmt.Sprintf("Temperature Trend (Next %d Hours)", hours)),
+		asciigraph.SeriesLegends(legends...),
+	}
+	if d.useColors {
+		options = append(options, asciigraph.SeriesColors(colors...))
+	}
+
+	return d.addTempLabels(asciigraph.PlotMany(series, options...), all)
+}
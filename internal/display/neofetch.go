@@ -71,14 +71,18 @@ func (d *NeofetchDisplay) buildInfoLines(data *api.WeatherData, showLocation boo
 
 	// Title with location (if enabled)
 	if showLocation {
-		location := data.Location.Name
-		if data.Location.Country != "" {
-			location += ", " + data.Location.Country
-		}
+		location := locationName(data.Location)
 		lines = append(lines, d.colorize(location, color.FgCyan, true))
 		lines = append(lines, d.colorize(strings.Repeat("-", len(location)), color.FgCyan, false))
 	}
 
+	// Last known data shown because fresh data is unavailable
+	if notice := staleNotice(data); notice != "" {
+		lines = append(lines, fmt.Sprintf("%s %s",
+			d.colorize("Offline:", color.FgYellow, true),
+			d.colorize(notice, color.FgYellow, false)))
+	}
+
 	// Condition
 	lines = append(lines, fmt.Sprintf("%s %s",
 		d.colorize("Weather:", color.FgBlue, true),
@@ -125,6 +129,34 @@ func (d *NeofetchDisplay) buildInfoLines(data *api.WeatherData, showLocation boo
 		d.colorize("Clouds:", color.FgBlue, true),
 		data.Current.CloudCover))
 
+	// UV index, when the provider reports it
+	if data.Current.UVIndex > 0 {
+		lines = append(lines, fmt.Sprintf("%s %.1f",
+			d.colorize("UV Index:", color.FgBlue, true),
+			data.Current.UVIndex))
+	}
+
+	// Minutely precipitation, when the provider reports it
+	if summary := precipitationSummary(data.Minutely); summary != "" {
+		lines = append(lines, fmt.Sprintf("%s %s",
+			d.colorize("Next hour:", color.FgBlue, true),
+			summary))
+	}
+
+	// Providers merged into an ensemble forecast
+	if len(data.Sources) > 1 {
+		lines = append(lines, fmt.Sprintf("%s %s",
+			d.colorize("Sources:", color.FgBlue, true),
+			strings.Join(data.Sources, ", ")))
+	}
+
+	// Government weather alerts
+	for _, alert := range data.Alerts {
+		lines = append(lines, fmt.Sprintf("%s %s",
+			d.colorize("Alert:", color.FgRed, true),
+			d.colorize(alert.Event, color.FgRed, false)))
+	}
+
 	return lines
 }
 
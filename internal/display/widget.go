@@ -33,6 +33,18 @@ func (d *WidgetDisplay) Render(data *api.WeatherData, showLocation bool, showHou
 		output.WriteString("\n\n")
 	}
 
+	// Last known data shown because fresh data is unavailable
+	if notice := staleNotice(data); notice != "" {
+		output.WriteString(d.renderStaleBanner(notice))
+		output.WriteString("\n\n")
+	}
+
+	// Government weather alerts
+	if len(data.Alerts) > 0 {
+		output.WriteString(d.renderAlerts(data))
+		output.WriteString("\n\n")
+	}
+
 	// Current weather
 	output.WriteString(d.renderCurrentWeather(data))
 	output.WriteString("\n\n")
@@ -46,17 +58,32 @@ func (d *WidgetDisplay) Render(data *api.WeatherData, showLocation bool, showHou
 	// Daily forecast
 	if showDaily && len(data.Daily) > 0 {
 		output.WriteString(d.renderDailyForecast(data))
+
+		// Forecast text for the next periods, when the provider has it
+		if details := d.renderForecastDetails(data); details != "" {
+			output.WriteString("\n\n")
+			output.WriteString(details)
+		}
 	}
 
 	return output.String()
 }
 
+// locationName returns the name of a location with its state, when known,
+// and country
+func locationName(loc api.Location) string {
+	name := loc.Name
+	for _, part := range []string{loc.State, loc.Country} {
+		if part != "" {
+			name += ", " + part
+		}
+	}
+	return name
+}
+
 // renderLocationHeader renders the location header
 func (d *WidgetDisplay) renderLocationHeader(data *api.WeatherData) string {
-	location := data.Location.Name
-	if data.Location.Country != "" {
-		location += ", " + data.Location.Country
-	}
+	location := locationName(data.Location)
 
 	style := lipgloss.NewStyle().
 		Bold(true).
@@ -71,6 +98,21 @@ func (d *WidgetDisplay) renderLocationHeader(data *api.WeatherData) string {
 	return style.Render(location)
 }
 
+// renderStaleBanner renders the notice that cached data is shown
+func (d *WidgetDisplay) renderStaleBanner(notice string) string {
+	style := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("11")).
+		PaddingLeft(2).
+		PaddingRight(2)
+
+	if !d.useColors {
+		style = lipgloss.NewStyle().Bold(true).PaddingLeft(2).PaddingRight(2)
+	}
+
+	return style.Render("⚠ Offline: showing data " + notice)
+}
+
 // renderCurrentWeather renders current weather in a box
 func (d *WidgetDisplay) renderCurrentWeather(data *api.WeatherData) string {
 	var content strings.Builder
@@ -93,10 +135,42 @@ func (d *WidgetDisplay) renderCurrentWeather(data *api.WeatherData) string {
 	content.WriteString(fmt.Sprintf("Pressure:     %d hPa\n", data.Current.Pressure))
 	content.WriteString(fmt.Sprintf("Clouds:       %d%%\n", data.Current.CloudCover))
 	content.WriteString(fmt.Sprintf("Visibility:   %.1f km", float64(data.Current.Visibility)/1000))
+	if data.Current.UVIndex > 0 {
+		content.WriteString(fmt.Sprintf("\nUV Index:     %.1f", data.Current.UVIndex))
+	}
+	if summary := precipitationSummary(data.Minutely); summary != "" {
+		content.WriteString(fmt.Sprintf("\nNext Hour:    %s", summary))
+	}
+	if len(data.Sources) > 1 {
+		content.WriteString(fmt.Sprintf("\nSources:      %s (median)", strings.Join(data.Sources, ", ")))
+	}
 
 	return d.renderBox("Current Weather", content.String(), lipgloss.Color("14"))
 }
 
+// renderAlerts renders government weather alerts
+func (d *WidgetDisplay) renderAlerts(data *api.WeatherData) string {
+	var content strings.Builder
+
+	wrap := lipgloss.NewStyle().Width(66)
+	for i, alert := range data.Alerts {
+		if i > 0 {
+			content.WriteString("\n\n")
+		}
+
+		content.WriteString(fmt.Sprintf("%s  (%s - %s)",
+			alert.Event, alert.Start.Format("Mon 15:04"), alert.End.Format("Mon 15:04")))
+		if alert.SenderName != "" {
+			content.WriteString("\n" + alert.SenderName)
+		}
+		if alert.Description != "" {
+			content.WriteString("\n" + wrap.Render(strings.TrimSpace(alert.Description)))
+		}
+	}
+
+	return d.renderBox("Weather Alerts", content.String(), lipgloss.Color("9"))
+}
+
 // renderHourlyForecast renders hourly forecast
 func (d *WidgetDisplay) renderHourlyForecast(data *api.WeatherData) string {
 	var content strings.Builder
@@ -121,6 +195,12 @@ func (d *WidgetDisplay) renderHourlyForecast(data *api.WeatherData) string {
 		content.WriteString(fmt.Sprintf("%s  %s  %.1f%s  %s",
 			timeStr, icon, hour.Temperature, tempUnit,
 			strings.Title(hour.Condition)))
+
+		// Ensemble forecasts show how far the providers disagree
+		if hour.Sources > 1 {
+			content.WriteString(fmt.Sprintf("  (%.0f–%.0f%s, %d sources)",
+				hour.TempSpread.Min, hour.TempSpread.Max, tempUnit, hour.Sources))
+		}
 	}
 
 	return d.renderBox("Hourly Forecast", content.String(), lipgloss.Color("11"))
@@ -152,6 +232,38 @@ func (d *WidgetDisplay) renderDailyForecast(data *api.WeatherData) string {
 	return d.renderBox("5-Day Forecast", content.String(), lipgloss.Color("13"))
 }
 
+// renderForecastDetails renders the detailed forecast text of the next
+// two named periods, e.g. "Tonight" and "Thursday"
+func (d *WidgetDisplay) renderForecastDetails(data *api.WeatherData) string {
+	var periods []string
+
+	for _, day := range data.Daily {
+		if day.PeriodName != "" && day.DetailedForecast != "" {
+			periods = append(periods, day.PeriodName+": "+day.DetailedForecast)
+		}
+		if day.NightPeriodName != "" && day.NightDetailedForecast != "" {
+			periods = append(periods, day.NightPeriodName+": "+day.NightDetailedForecast)
+		}
+		if len(periods) >= 2 {
+			break
+		}
+	}
+
+	if len(periods) == 0 {
+		return ""
+	}
+	if len(periods) > 2 {
+		periods = periods[:2]
+	}
+
+	wrap := lipgloss.NewStyle().Width(66)
+	for i, p := range periods {
+		periods[i] = wrap.Render(p)
+	}
+
+	return d.renderBox("Forecast Details", strings.Join(periods, "\n\n"), lipgloss.Color("10"))
+}
+
 // renderBox renders content in a bordered box
 func (d *WidgetDisplay) renderBox(title, content string, color lipgloss.Color) string {
 	titleStyle := lipgloss.NewStyle().
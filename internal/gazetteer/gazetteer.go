@@ -0,0 +1,280 @@
+// Package gazetteer is an embedded city database that resolves place names
+// and coordinates without the network. The table comes from GeoNames and
+// is rebuilt with cmd/gengazetteer.
+package gazetteer
+
+import (
+	_ "embed"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:generate go run ../../cmd/gengazetteer -out cities.tsv
+
+//go:embed cities.tsv
+var citiesTSV string
+
+// City is a place in the gazetteer
+type City struct {
+	Name       string
+	ASCIIName  string
+	Alternates []string
+	Latitude   float64
+	Longitude  float64
+	Country    string // ISO 3166-1 alpha-2 code
+	Admin1Code string // GeoNames code of the state or region, e.g. "CA"
+	Admin1     string // name of the state or region
+	Population int
+	Timezone   string
+}
+
+var (
+	loadOnce sync.Once
+	cities   []City
+	keys     [][]string // normalized names of each city
+)
+
+// load parses the embedded table once
+func load() {
+	loadOnce.Do(func() {
+		for _, line := range strings.Split(citiesTSV, "\n") {
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			c, ok := parseLine(line)
+			if !ok {
+				continue
+			}
+			cities = append(cities, c)
+
+			names := []string{Normalize(c.Name), Normalize(c.ASCIIName)}
+			for _, alt := range c.Alternates {
+				names = append(names, Normalize(alt))
+			}
+			keys = append(keys, names)
+		}
+	})
+}
+
+// parseLine parses one row of the table
+func parseLine(line string) (City, bool) {
+	f := strings.Split(line, "\t")
+	if len(f) != 10 {
+		return City{}, false
+	}
+
+	lat, err1 := strconv.ParseFloat(f[3], 64)
+	lon, err2 := strconv.ParseFloat(f[4], 64)
+	pop, err3 := strconv.Atoi(f[8])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return City{}, false
+	}
+
+	var alternates []string
+	if f[2] != "" {
+		alternates = strings.Split(f[2], ",")
+	}
+
+	return City{
+		Name:       f[0],
+		ASCIIName:  f[1],
+		Alternates: alternates,
+		Latitude:   lat,
+		Longitude:  lon,
+		Country:    f[5],
+		Admin1Code: f[6],
+		Admin1:     f[7],
+		Population: pop,
+		Timezone:   f[9],
+	}, true
+}
+
+// Len returns the number of cities in the gazetteer
+func Len() int {
+	load()
+	return len(cities)
+}
+
+// Search returns up to limit cities matching name, best match first and
+// larger cities before smaller ones. Names match ignoring case and
+// accents, by prefix, and with a typo or two in longer names.
+//
+// A non-empty state prefers cities in that state or region, given by code
+// or name; like "City,XX" input it may also be a country code. A non-empty
+// country keeps only cities in that country.
+func Search(name, state, country string, limit int) []City {
+	load()
+
+	query := Normalize(name)
+	if query == "" {
+		return nil
+	}
+
+	type match struct {
+		city  int
+		score int
+	}
+	var all, inState []match
+	for i, c := range cities {
+		if country != "" && !strings.EqualFold(c.Country, country) {
+			continue
+		}
+		score := matchScore(query, keys[i])
+		if score < 0 {
+			continue
+		}
+		all = append(all, match{i, score})
+		if state != "" && inRegion(c, state) {
+			inState = append(inState, match{i, score})
+		}
+	}
+
+	matches := all
+	if len(inState) > 0 {
+		matches = inState
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return cities[matches[i].city].Population > cities[matches[j].city].Population
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]City, len(matches))
+	for i, m := range matches {
+		result[i] = cities[m.city]
+	}
+	return result
+}
+
+// Nearest returns the city closest to coordinates, if there is one within
+// maxKm kilometres
+func Nearest(lat, lon, maxKm float64) (City, bool) {
+	load()
+
+	best, bestKm := -1, maxKm
+	for i, c := range cities {
+		if km := distanceKm(lat, lon, c.Latitude, c.Longitude); km <= bestKm {
+			best, bestKm = i, km
+		}
+	}
+	if best < 0 {
+		return City{}, false
+	}
+	return cities[best], true
+}
+
+// matchScore rates how well query matches any of names: 0 for an exact
+// match, 1 for a prefix, and 1 plus the edit distance for a near miss.
+// It returns -1 for no match.
+func matchScore(query string, names []string) int {
+	best := -1
+	better := func(score int) {
+		if best < 0 || score < best {
+			best = score
+		}
+	}
+
+	maxTypos := 0
+	switch n := len([]rune(query)); {
+	case n >= 8:
+		maxTypos = 2
+	case n >= 4:
+		maxTypos = 1
+	}
+
+	for _, name := range names {
+		switch {
+		case name == query:
+			return 0
+		case len(query) >= 3 && strings.HasPrefix(name, query):
+			better(1)
+		case maxTypos > 0:
+			if d := Levenshtein(query, name, maxTypos); d >= 0 {
+				better(1 + d)
+			}
+		}
+	}
+	return best
+}
+
+// inRegion reports whether a city is in the given state, region or country
+func inRegion(c City, state string) bool {
+	return strings.EqualFold(c.Admin1Code, state) ||
+		strings.EqualFold(c.Country, state) ||
+		Normalize(c.Admin1) == Normalize(state)
+}
+
+// Normalize lowercases a name and strips accents and punctuation so that
+// "São Paulo", "sao paulo" and "Sao-Paulo" compare equal
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accent
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Levenshtein returns the edit distance between a and b, or -1 if it is
+// larger than max
+func Levenshtein(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return -1
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > max {
+			return -1
+		}
+		prev, cur = cur, prev
+	}
+
+	if prev[len(rb)] > max {
+		return -1
+	}
+	return prev[len(rb)]
+}
+
+// distanceKm returns the great-circle distance between two points
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
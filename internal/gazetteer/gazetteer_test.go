@@ -0,0 +1,91 @@
+package gazetteer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   string
+		country string
+		want    string // "Name, Admin1, Country" of the best match
+	}{
+		{"New York", "", "", "New York City, New York, US"},
+		{"nyc", "", "", "New York City, New York, US"},
+		{"Springfield", "", "", "Springfield, Missouri, US"},
+		{"Springfield", "IL", "", "Springfield, Illinois, US"},
+		{"Springfield", "Massachusetts", "", "Springfield, Massachusetts, US"},
+		{"Paris", "", "", "Paris, Île-de-France, FR"},
+		{"Paris", "TX", "", "Paris, Texas, US"},
+		{"Paris", "", "US", "Paris, Texas, US"},
+		{"London", "ON", "", "London, England, GB"}, // unknown state code falls back to all matches
+		{"London", "Ontario", "", "London, Ontario, CA"},
+		{"sao paulo", "", "", "São Paulo, São Paulo, BR"},
+		{"Zurich", "", "", "Zürich, Zurich, CH"},
+		{"Munich", "", "", "München, Bavaria, DE"},
+		{"Sprngfield", "MO", "", "Springfield, Missouri, US"},
+		{"Philadelpia", "", "", "Philadelphia, Pennsylvania, US"},
+		{"San Fran", "", "", "San Francisco, California, US"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+","+tt.state+","+tt.country, func(t *testing.T) {
+			results := Search(tt.name, tt.state, tt.country, 5)
+			if len(results) == 0 {
+				t.Fatalf("Expected %s, got no results", tt.want)
+			}
+			c := results[0]
+			if got := c.Name + ", " + c.Admin1 + ", " + c.Country; got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSearchNoMatch(t *testing.T) {
+	for _, name := range []string{"", "Nowhere", "Xyzzy", "Ny"} {
+		if results := Search(name, "", "", 5); len(results) != 0 {
+			t.Errorf("Expected no results for %q, got %+v", name, results)
+		}
+	}
+	if results := Search("Tokyo", "", "US", 5); len(results) != 0 {
+		t.Errorf("Expected no Tokyo in the US, got %+v", results)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	c, ok := Nearest(40.7306, -73.9352, 25)
+	if !ok || c.Name != "New York City" {
+		t.Errorf("Expected New York City, got %+v", c)
+	}
+
+	if c, ok := Nearest(0, -30, 100); ok {
+		t.Errorf("Expected nothing in the middle of the Atlantic, got %+v", c)
+	}
+}
+
+func TestTable(t *testing.T) {
+	// cities15000 has over 25,000 rows; the hand-picked fallback table a
+	// few hundred
+	minCities := 100
+	if strings.Contains(citiesTSV, "# Generated by cmd/gengazetteer") {
+		minCities = 20000
+		// A generated table names the dumps it was built from
+		if strings.Count(citiesTSV, "# Source: ") != 2 || !strings.Contains(citiesTSV, "SHA-256 ") {
+			t.Error("Expected the table header to record both sources and their SHA-256")
+		}
+	}
+	if Len() < minCities {
+		t.Fatalf("Expected at least %d cities in the embedded table, got %d", minCities, Len())
+	}
+	for _, c := range cities {
+		if c.Name == "" || c.Country == "" || c.Timezone == "" {
+			t.Errorf("Incomplete row %+v", c)
+		}
+		if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+			t.Errorf("Coordinates out of range for %s: %f,%f", c.Name, c.Latitude, c.Longitude)
+		}
+	}
+}
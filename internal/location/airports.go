@@ -0,0 +1,67 @@
+package location
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed airports.tsv
+var airportsTSV string
+
+// Airport is an airport in the embedded table
+type Airport struct {
+	IATA      string
+	ICAO      string
+	Name      string
+	City      string
+	Country   string // ISO 3166-1 alpha-2 code
+	Latitude  float64
+	Longitude float64
+}
+
+var (
+	airportsOnce sync.Once
+	airports     map[string]Airport // keyed by IATA and ICAO code
+)
+
+// loadAirports parses the embedded table once
+func loadAirports() {
+	airportsOnce.Do(func() {
+		airports = make(map[string]Airport)
+		for _, line := range strings.Split(airportsTSV, "\n") {
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			f := strings.Split(line, "\t")
+			if len(f) != 7 {
+				continue
+			}
+			lat, err1 := strconv.ParseFloat(f[5], 64)
+			lon, err2 := strconv.ParseFloat(f[6], 64)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+
+			a := Airport{
+				IATA:      f[0],
+				ICAO:      f[1],
+				Name:      f[2],
+				City:      f[3],
+				Country:   f[4],
+				Latitude:  lat,
+				Longitude: lon,
+			}
+			airports[a.IATA] = a
+			airports[a.ICAO] = a
+		}
+	})
+}
+
+// LookupAirport finds an airport by its IATA or ICAO code, ignoring case
+func LookupAirport(code string) (Airport, bool) {
+	loadAirports()
+	a, ok := airports[strings.ToUpper(strings.TrimSpace(code))]
+	return a, ok
+}
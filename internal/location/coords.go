@@ -0,0 +1,261 @@
+package location
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Coordinates are read as two components, each a number of degrees with
+// optional minutes and seconds and an optional hemisphere letter:
+//
+//	40.7128,-74.0060
+//	40.7128 -74.0060
+//	40.7128 N, 74.0060 W
+//	N40.7128 W74.0060
+//	40°42.767'N 74°0.367'W
+//	40°42'46"N 74°0'22"W
+//
+// A hemisphere letter decides which component is the latitude, so
+// "74.0060 W 40.7128 N" works too.
+
+// coordSymbols maps the typographic marks pasted from maps and documents to
+// the plain ones the grammar uses
+var coordSymbols = strings.NewReplacer(
+	"º", "°", "˚", "°",
+	"′", "'", "’", "'", "‘", "'",
+	"″", `"`, "”", `"`, "“", `"`, "''", `"`,
+)
+
+// coordComponent is one half of a coordinate pair as written
+type coordComponent struct {
+	hemisphere byte // 'N', 'S', 'E', 'W' or 0
+	negative   bool
+	degrees    string
+	minutes    string
+	seconds    string
+}
+
+// parseCoords tries to parse input as a coordinate pair. Input that is not
+// shaped like coordinates returns false; input that is, but has a value out
+// of range or a malformed part, returns an error so it is not mistaken for
+// a city name.
+func parseCoords(input string) (*ParsedLocation, bool, error) {
+	s := strings.ToUpper(coordSymbols.Replace(input))
+
+	// Only a degree, minute or second mark after a number commits the
+	// input to being coordinates; an apostrophe in "St. John's" does not
+	comps, marked, ok := scanCoords(s)
+	if !ok {
+		if marked {
+			return nil, false, fmt.Errorf("cannot read %q as coordinates, e.g. 40°42'46\"N 74°0'22\"W", input)
+		}
+		return nil, false, nil
+	}
+	if len(comps) != 2 {
+		if marked || len(comps) == 1 && comps[0].hemisphere != 0 {
+			return nil, false, fmt.Errorf("%q needs both a latitude and a longitude", input)
+		}
+		return nil, false, nil
+	}
+
+	lat, lon := comps[0], comps[1]
+	switch {
+	case isLatitude(lat.hemisphere) && isLatitude(lon.hemisphere):
+		return nil, false, fmt.Errorf("%q gives two latitudes (N/S); one must be a longitude (E/W)", input)
+	case isLongitude(lat.hemisphere) && isLongitude(lon.hemisphere):
+		return nil, false, fmt.Errorf("%q gives two longitudes (E/W); one must be a latitude (N/S)", input)
+	case isLongitude(lat.hemisphere) || isLatitude(lon.hemisphere):
+		lat, lon = lon, lat
+	}
+
+	latitude, err := lat.value("latitude", 90)
+	if err != nil {
+		return nil, false, err
+	}
+	longitude, err := lon.value("longitude", 180)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &ParsedLocation{
+		Type:       TypeCoords,
+		Latitude:   latitude,
+		Longitude:  longitude,
+		Confidence: ConfidenceCertain,
+	}, true, nil
+}
+
+// scanCoords splits normalized input into coordinate components. It
+// returns false as soon as it meets something that cannot be part of a
+// coordinate, such as a letter other than a hemisphere. marked reports
+// whether a degree, minute or second mark followed a number before that.
+func scanCoords(s string) (comps []coordComponent, marked bool, ok bool) {
+	var cur *coordComponent
+	separators := 0
+
+	closeCur := func() {
+		if cur != nil {
+			comps = append(comps, *cur)
+			cur = nil
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c == ',' || c == ';':
+			// One separator, between the two components
+			first := cur != nil && cur.degrees != "" && len(comps) == 0 ||
+				cur == nil && len(comps) == 1
+			if !first || separators > 0 {
+				return nil, marked, false
+			}
+			separators++
+			closeCur()
+			i++
+
+		case c == 'N' || c == 'S' || c == 'E' || c == 'W':
+			if cur != nil && cur.degrees != "" && cur.hemisphere == 0 {
+				// Suffix: "40.7128 N"
+				cur.hemisphere = c
+				closeCur()
+			} else {
+				// Prefix: "N40.7128"
+				if cur != nil && cur.degrees == "" {
+					return nil, marked, false
+				}
+				closeCur()
+				cur = &coordComponent{hemisphere: c}
+			}
+			i++
+
+		case c == '-' || c == '+' || c == '.' || c >= '0' && c <= '9':
+			// A sign only starts a component, so "100-0001" is not a pair
+			if (c == '-' || c == '+') && i > 0 && s[i-1] != ' ' && s[i-1] != '\t' && s[i-1] != ',' && s[i-1] != ';' {
+				return nil, marked, false
+			}
+			negative := c == '-'
+			if c == '-' || c == '+' {
+				i++
+			}
+			start := i
+			for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+				i++
+			}
+			num := s[start:i]
+			if num == "" || strings.Count(num, ".") > 1 || num == "." {
+				return nil, marked, false
+			}
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+			var mark byte
+			if i < len(s) && strings.IndexByte(`'"`, s[i]) >= 0 {
+				mark = s[i]
+				i++
+			} else if strings.HasPrefix(s[i:], "°") {
+				mark = 'd'
+				i += len("°")
+			}
+			if mark != 0 {
+				marked = true
+			}
+
+			switch mark {
+			case 0, 'd':
+				// Degrees start a new component unless one is waiting
+				// for them after a hemisphere prefix
+				if cur == nil || cur.degrees != "" {
+					closeCur()
+					cur = &coordComponent{}
+				}
+				cur.degrees = num
+				cur.negative = negative
+			case '\'':
+				if cur == nil || cur.degrees == "" || cur.minutes != "" || negative {
+					return nil, marked, false
+				}
+				cur.minutes = num
+			case '"':
+				if cur == nil || cur.minutes == "" || cur.seconds != "" || negative {
+					return nil, marked, false
+				}
+				cur.seconds = num
+			}
+
+		default:
+			return nil, marked, false
+		}
+	}
+	closeCur()
+
+	for _, comp := range comps {
+		if comp.degrees == "" {
+			return nil, marked, false
+		}
+	}
+	return comps, marked, true
+}
+
+// value converts a component to signed decimal degrees, checking each part
+// is in range
+func (c coordComponent) value(axis string, limit float64) (float64, error) {
+	deg, err := strconv.ParseFloat(c.degrees, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s degrees %q", axis, c.degrees)
+	}
+
+	if c.minutes != "" {
+		if strings.Contains(c.degrees, ".") {
+			return 0, fmt.Errorf("%s degrees %s must be whole when minutes are given", axis, c.degrees)
+		}
+		mins, err := strconv.ParseFloat(c.minutes, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s minutes %q", axis, c.minutes)
+		}
+		if mins >= 60 {
+			return 0, fmt.Errorf("%s minutes %s must be less than 60", axis, c.minutes)
+		}
+		deg += mins / 60
+	}
+
+	if c.seconds != "" {
+		if strings.Contains(c.minutes, ".") {
+			return 0, fmt.Errorf("%s minutes %s must be whole when seconds are given", axis, c.minutes)
+		}
+		sec, err := strconv.ParseFloat(c.seconds, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s seconds %q", axis, c.seconds)
+		}
+		if sec >= 60 {
+			return 0, fmt.Errorf("%s seconds %s must be less than 60", axis, c.seconds)
+		}
+		deg += sec / 3600
+	}
+
+	if c.negative && c.hemisphere != 0 {
+		return 0, fmt.Errorf("%s -%s %c has both a minus sign and a hemisphere", axis, c.degrees, c.hemisphere)
+	}
+	if c.negative || c.hemisphere == 'S' || c.hemisphere == 'W' {
+		deg = -deg
+	}
+
+	if deg < -limit || deg > limit {
+		return 0, fmt.Errorf("%s %s is out of range, must be between -%g and %g", axis, strconv.FormatFloat(deg, 'f', -1, 64), limit, limit)
+	}
+	return deg, nil
+}
+
+// isLatitude reports whether a hemisphere letter marks a latitude
+func isLatitude(h byte) bool {
+	return h == 'N' || h == 'S'
+}
+
+// isLongitude reports whether a hemisphere letter marks a longitude
+func isLongitude(h byte) bool {
+	return h == 'E' || h == 'W'
+}
@@ -0,0 +1,98 @@
+package location
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseCoordinateGrammar(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expectLat float64
+		expectLon float64
+	}{
+		{"space separated", "40.7128 -74.0060", 40.7128, -74.0060},
+		{"semicolon", "40.7128; -74.0060", 40.7128, -74.0060},
+		{"explicit plus sign", "+40.7128,-74.0060", 40.7128, -74.0060},
+		{"hemisphere suffix", "40.7128 N, 74.0060 W", 40.7128, -74.0060},
+		{"hemisphere suffix no spaces", "33.8688S 151.2093E", -33.8688, 151.2093},
+		{"hemisphere prefix", "N40.7128 W74.0060", 40.7128, -74.0060},
+		{"hemisphere lowercase", "40.7128n 74.0060w", 40.7128, -74.0060},
+		{"longitude first", "74.0060 W, 40.7128 N", 40.7128, -74.0060},
+		{"decimal degrees with marks", "40.7128° N, 74.0060° W", 40.7128, -74.0060},
+		{"decimal minutes", "40°42.768'N 74°0.36'W", 40.7128, -74.0060},
+		{"DMS", `40°42'46"N 74°0'22"W`, 40.712778, -74.006111},
+		{"DMS with spaces", `40° 42' 46" N, 74° 0' 22" W`, 40.712778, -74.006111},
+		{"DMS typographic marks", "40°42′46″N 74°0′22″W", 40.712778, -74.006111},
+		{"DMS double apostrophe", "40°42'46''N 74°0'22''W", 40.712778, -74.006111},
+		{"DMS signed", `-33°52'8" 151°12'33"`, -33.868889, 151.209167},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if loc.Type != TypeCoords {
+				t.Fatalf("Expected type TypeCoords, got %v", loc.Type)
+			}
+			if math.Abs(loc.Latitude-tt.expectLat) > 1e-5 || math.Abs(loc.Longitude-tt.expectLon) > 1e-5 {
+				t.Errorf("Expected %f,%f, got %f,%f", tt.expectLat, tt.expectLon, loc.Latitude, loc.Longitude)
+			}
+		})
+	}
+}
+
+func TestParseCoordinateErrors(t *testing.T) {
+	tests := []struct {
+		input     string
+		expectErr string
+	}{
+		{"100,200", "latitude 100 is out of range"},
+		{"40.7128,-190", "longitude -190 is out of range"},
+		{"91 N, 10 E", "latitude 91 is out of range"},
+		{"10 E, 200 N", "latitude 200 is out of range"},
+		{`40°75'N 74°0'W`, "latitude minutes 75 must be less than 60"},
+		{`40°42'61"N 74°0'22"W`, "latitude seconds 61 must be less than 60"},
+		{`40.5°42'N 74°0'W`, "latitude degrees 40.5 must be whole"},
+		{"40 N, 74 S", "two latitudes"},
+		{"40 E 74 W", "two longitudes"},
+		{"-40 S, 74 E", "both a minus sign and a hemisphere"},
+		{"40.7128 N", "needs both a latitude and a longitude"},
+		{"40°42'46\"N", "needs both a latitude and a longitude"},
+		{"40°42'46\"N 74°0'22\"Q", "cannot read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestParseCoordinateLookalikes(t *testing.T) {
+	for _, input := range []string{"90210", "10001,US", "12345-6789", "100-0001", "1 2 3", "New York", "Sydney, NSW", "W"} {
+		loc, err := Parse(input)
+		if err == nil && loc.Type == TypeCoords {
+			t.Errorf("Expected %q not to parse as coordinates, got %+v", input, loc)
+		}
+	}
+
+	// Apostrophes in place names are not minute marks
+	for _, input := range []string{"St. John's,NL", "Coeur d'Alene,ID", "L'Aquila", "Martha's Vineyard", "'s-Hertogenbosch"} {
+		loc, err := Parse(input)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", input, err)
+			continue
+		}
+		if loc.Type != TypeCity {
+			t.Errorf("Expected %q to parse as a city, got %+v", input, loc)
+		}
+	}
+}
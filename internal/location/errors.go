@@ -0,0 +1,154 @@
+package location
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/james-see/weatherornot/internal/gazetteer"
+)
+
+// maxSuggestions is how many "did you mean" suggestions are offered
+const maxSuggestions = 3
+
+// ParseError explains why input could not be read as a location
+type ParseError struct {
+	Input       string
+	Reason      string   // what was wrong or ambiguous
+	Suggestions []string // locations the input may have been meant as
+}
+
+// Error returns the reason, followed by any suggestions
+func (e *ParseError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s; did you mean %s?", e.Reason, QuoteList(e.Suggestions))
+}
+
+// Suggest returns up to three locations input may have been meant as:
+// known names close to it first, then cities from the gazetteer
+func (p Parser) Suggest(input string) []string {
+	query := gazetteer.Normalize(input)
+	if query == "" {
+		return nil
+	}
+
+	var suggestions []string
+	seen := map[string]bool{query: true}
+	add := func(s string) {
+		if key := gazetteer.Normalize(s); !seen[key] && len(suggestions) < maxSuggestions {
+			seen[key] = true
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	for _, known := range p.Known {
+		if isClose(query, gazetteer.Normalize(known)) {
+			add(known)
+		}
+	}
+
+	parts := strings.Split(input, ",")
+	region := ""
+	if len(parts) > 1 {
+		region = strings.TrimSpace(parts[1])
+	}
+	for _, c := range gazetteer.Search(parts[0], region, "", maxSuggestions) {
+		add(cityLabel(c))
+	}
+	return suggestions
+}
+
+// suggestCountries returns the input rewritten with the countries whose
+// names are closest to an unknown country
+func suggestCountries(city, state, country string) []string {
+	loadISO()
+	query := gazetteer.Normalize(country)
+
+	type match struct {
+		code     string
+		distance int
+	}
+	best := make(map[string]int)
+	for key, c := range countries {
+		if key != strings.ToLower(key) {
+			// Codes are stored in capitals; only names are compared
+			continue
+		}
+		// One more typo than usual, as a swapped pair of letters counts as two
+		d := gazetteer.Levenshtein(query, key, maxTypos(query)+1)
+		if prev, ok := best[c.Alpha2]; d >= 0 && (!ok || d < prev) {
+			best[c.Alpha2] = d
+		}
+	}
+	matches := make([]match, 0, len(best))
+	for code, d := range best {
+		matches = append(matches, match{code, d})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].code < matches[j].code
+	})
+
+	var suggestions []string
+	for _, m := range matches {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, city+","+state+","+m.code)
+	}
+	return suggestions
+}
+
+// isClose reports whether a normalized name is a prefix of, or a typo or
+// two away from, a normalized candidate
+func isClose(query, candidate string) bool {
+	if candidate == "" {
+		return false
+	}
+	if len(query) >= 3 && strings.HasPrefix(candidate, query) {
+		return true
+	}
+	return gazetteer.Levenshtein(query, candidate, maxTypos(query)) >= 0
+}
+
+// maxTypos is how many typos a name of this length may have and still
+// match, the same allowance the gazetteer makes
+func maxTypos(s string) int {
+	switch n := len([]rune(s)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+// cityLabel writes a gazetteer city as location input, with the state for
+// US cities, e.g. "Springfield,IL,US" or "Paris,FR"
+func cityLabel(c gazetteer.City) string {
+	if c.Country == "US" && c.Admin1Code != "" {
+		return c.Name + "," + c.Admin1Code + "," + c.Country
+	}
+	return c.Name + "," + c.Country
+}
+
+// QuoteList joins quoted strings as a readable list: "a", "b" or "c"
+func QuoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+	return joinOr(quoted)
+}
+
+// joinOr joins items as "a, b or c"
+func joinOr(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
@@ -0,0 +1,299 @@
+package location
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Geohashes, Open Location Codes (Plus Codes) and Maidenhead locators all
+// name a rectangular cell of the globe; decoding returns the cell center.
+
+const (
+	geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+	geohashPrefix   = "geo:"
+
+	plusCodeAlphabet   = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator  = '+'
+	plusCodePadding    = '0'
+	plusCodePairDigits = 10 // digits encoded as latitude/longitude pairs
+	plusCodeMaxDigits  = 15
+	plusCodeGridRows   = 5
+	plusCodeGridCols   = 4
+	// Integer units per degree at the finest precision
+	plusCodeLatUnits = 8000 * 3125 // 20^3 pairs resolution times 5^5 grid rows
+	plusCodeLonUnits = 8000 * 1024 // 20^3 pairs resolution times 4^5 grid columns
+)
+
+var (
+	plusCodeRegex   = regexp.MustCompile(`^[23456789CFGHJMPQRVWX0]{2,8}\+[23456789CFGHJMPQRVWX]*$`)
+	maidenheadRegex = regexp.MustCompile(`^[A-R]{2}(?:[0-9]{2}(?:[A-X]{2}(?:[0-9]{2})?)?)?$`)
+)
+
+// parseGrid tries to parse input as a geohash ("geo:9q8yy"), a Plus Code
+// ("849VCWC8+R9") or a Maidenhead locator ("CM87wj"). Input that is clearly
+// meant as one of these but does not decode is an error.
+func parseGrid(input string) (*ParsedLocation, bool, error) {
+	var lat, lon float64
+	var err error
+
+	upper := strings.ToUpper(input)
+	switch {
+	case strings.HasPrefix(strings.ToLower(input), geohashPrefix):
+		rest := strings.TrimSpace(input[len(geohashPrefix):])
+		// A geo: URI such as "geo:37.78,-122.41" carries plain coordinates
+		if loc, ok, err := parseCoords(rest); ok || err != nil {
+			return loc, ok, err
+		}
+		lat, lon, err = DecodeGeohash(rest)
+	case plusCodeRegex.MatchString(upper):
+		lat, lon, err = DecodePlusCode(upper)
+	case len(upper) >= 4 && maidenheadRegex.MatchString(upper):
+		// A bare two-letter field would swallow state codes like "NY"
+		lat, lon, err = DecodeMaidenhead(input)
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &ParsedLocation{
+		Type:       TypeCoords,
+		Latitude:   lat,
+		Longitude:  lon,
+		Confidence: ConfidenceCertain,
+	}, true, nil
+}
+
+// EncodeGeohash returns the geohash of coordinates with precision
+// characters; 9 characters are about 5 metres across
+func EncodeGeohash(lat, lon float64, precision int) string {
+	precision = max(1, min(precision, 12))
+	latMin, latMax := -90.0, 90.0
+	lonMin, lonMax := -180.0, 180.0
+
+	var b strings.Builder
+	even := true // bits alternate, starting with longitude
+	bit, ch := 0, 0
+	for b.Len() < precision {
+		if even {
+			mid := (lonMin + lonMax) / 2
+			if lon >= mid {
+				ch = ch<<1 | 1
+				lonMin = mid
+			} else {
+				ch <<= 1
+				lonMax = mid
+			}
+		} else {
+			mid := (latMin + latMax) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				latMin = mid
+			} else {
+				ch <<= 1
+				latMax = mid
+			}
+		}
+		even = !even
+
+		if bit++; bit == 5 {
+			b.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return b.String()
+}
+
+// DecodeGeohash returns the center of a geohash cell
+func DecodeGeohash(hash string) (float64, float64, error) {
+	if hash == "" {
+		return 0, 0, fmt.Errorf("empty geohash")
+	}
+	if len(hash) > 12 {
+		return 0, 0, fmt.Errorf("geohash %q is longer than 12 characters", hash)
+	}
+
+	latMin, latMax := -90.0, 90.0
+	lonMin, lonMax := -180.0, 180.0
+	even := true
+	for _, r := range strings.ToLower(hash) {
+		ch := strings.IndexRune(geohashAlphabet, r)
+		if ch < 0 {
+			return 0, 0, fmt.Errorf("invalid geohash character %q in %q", r, hash)
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			if even {
+				mid := (lonMin + lonMax) / 2
+				if ch&mask != 0 {
+					lonMin = mid
+				} else {
+					lonMax = mid
+				}
+			} else {
+				mid := (latMin + latMax) / 2
+				if ch&mask != 0 {
+					latMin = mid
+				} else {
+					latMax = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return (latMin + latMax) / 2, (lonMin + lonMax) / 2, nil
+}
+
+// EncodePlusCode returns the Open Location Code of coordinates with the
+// given number of digits. The standard 10 digits are about 14 metres
+// across; shorter codes are padded, e.g. "849V0000+".
+func EncodePlusCode(lat, lon float64, digits int) string {
+	digits = max(2, min(digits, plusCodeMaxDigits))
+	if digits < plusCodePairDigits && digits%2 == 1 {
+		digits++
+	}
+
+	lat = math.Max(-90, math.Min(lat, 90))
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	latVal := int64(math.Round((lat+90)*plusCodeLatUnits*1e6) / 1e6)
+	lonVal := int64(math.Round(lon*plusCodeLonUnits*1e6) / 1e6)
+	// The north pole belongs to the cell below it
+	latVal = min(latVal, 180*plusCodeLatUnits-1)
+
+	code := make([]byte, plusCodeMaxDigits)
+	for i := 0; i < plusCodeMaxDigits-plusCodePairDigits; i++ {
+		row, col := latVal%plusCodeGridRows, lonVal%plusCodeGridCols
+		code[plusCodeMaxDigits-1-i] = plusCodeAlphabet[row*plusCodeGridCols+col]
+		latVal /= plusCodeGridRows
+		lonVal /= plusCodeGridCols
+	}
+	for i := 0; i < plusCodePairDigits/2; i++ {
+		code[plusCodePairDigits-1-2*i] = plusCodeAlphabet[lonVal%20]
+		code[plusCodePairDigits-2-2*i] = plusCodeAlphabet[latVal%20]
+		latVal /= 20
+		lonVal /= 20
+	}
+
+	if digits < 8 {
+		for i := digits; i < 8; i++ {
+			code[i] = plusCodePadding
+		}
+		return string(code[:8]) + string(plusCodeSeparator)
+	}
+	return string(code[:8]) + string(plusCodeSeparator) + string(code[8:digits])
+}
+
+// DecodePlusCode returns the center of a full Open Location Code. Short
+// codes such as "CWC8+R9", which need a nearby town to resolve, are an
+// error.
+func DecodePlusCode(code string) (float64, float64, error) {
+	code = strings.ToUpper(code)
+	sep := strings.IndexByte(code, plusCodeSeparator)
+	if sep < 0 || strings.Count(code, string(plusCodeSeparator)) != 1 {
+		return 0, 0, fmt.Errorf("plus code %q must contain one %q", code, plusCodeSeparator)
+	}
+	if sep != 8 {
+		return 0, 0, fmt.Errorf("%q is a short plus code; use the full code, e.g. \"849VCWC8+R9\"", code)
+	}
+
+	digits := code[:sep]
+	if pad := strings.IndexByte(digits, plusCodePadding); pad >= 0 {
+		if pad == 0 || pad%2 == 1 || strings.Trim(digits[pad:], string(plusCodePadding)) != "" || len(code) > sep+1 {
+			return 0, 0, fmt.Errorf("invalid padding in plus code %q", code)
+		}
+		digits = digits[:pad]
+	}
+	if len(code) == sep+2 {
+		return 0, 0, fmt.Errorf("plus code %q cannot have a single digit after %q", code, plusCodeSeparator)
+	}
+	digits += code[sep+1:]
+	if len(digits) > plusCodeMaxDigits {
+		digits = digits[:plusCodeMaxDigits]
+	}
+
+	values := make([]int, len(digits))
+	for i := 0; i < len(digits); i++ {
+		v := strings.IndexByte(plusCodeAlphabet, digits[i])
+		if v < 0 {
+			return 0, 0, fmt.Errorf("invalid plus code character %q in %q", digits[i], code)
+		}
+		values[i] = v
+	}
+	if values[0] >= 9 || values[1] >= 18 {
+		return 0, 0, fmt.Errorf("plus code %q is outside the valid range", code)
+	}
+
+	lat, lon := -90.0, -180.0
+	size := 20.0 * 20 // divided by 20 before each pair; the first spans 20°
+	i := 0
+	for ; i < len(values) && i < plusCodePairDigits; i += 2 {
+		size /= 20
+		lat += float64(values[i]) * size
+		lon += float64(values[i+1]) * size
+	}
+	latSize, lonSize := size, size
+	for ; i < len(values); i++ {
+		latSize /= plusCodeGridRows
+		lonSize /= plusCodeGridCols
+		lat += float64(values[i]/plusCodeGridCols) * latSize
+		lon += float64(values[i]%plusCodeGridCols) * lonSize
+	}
+
+	return math.Min(lat+latSize/2, 90), lon + lonSize/2, nil
+}
+
+// EncodeMaidenhead returns the Maidenhead locator of coordinates with the
+// given number of characters: 4 for a square, 6 for a subsquare such as
+// "CM87wj" (about 5 by 2.5 arc minutes) or 8 for an extended square
+func EncodeMaidenhead(lat, lon float64, length int) string {
+	length = max(2, min(length, 8)) &^ 1
+	lat = math.Max(0, math.Min(lat+90, 180-1e-9))
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+
+	// Each pair splits the previous cell: fields of 20°x10°, squares of
+	// 2°x1°, subsquares of 5'x2.5' and extended squares of 30"x15"
+	divisions := []float64{18, 10, 24, 10}
+	bases := []byte{'A', '0', 'a', '0'}
+	lonSize, latSize := 360.0, 180.0
+
+	var b strings.Builder
+	for i := 0; i < length/2; i++ {
+		lonSize /= divisions[i]
+		latSize /= divisions[i]
+		x, y := int(lon/lonSize), int(lat/latSize)
+		b.WriteByte(bases[i] + byte(x))
+		b.WriteByte(bases[i] + byte(y))
+		lon -= float64(x) * lonSize
+		lat -= float64(y) * latSize
+	}
+	return b.String()
+}
+
+// DecodeMaidenhead returns the center of a Maidenhead locator cell of 2,
+// 4, 6 or 8 characters
+func DecodeMaidenhead(locator string) (float64, float64, error) {
+	upper := strings.ToUpper(locator)
+	if !maidenheadRegex.MatchString(upper) {
+		return 0, 0, fmt.Errorf("invalid Maidenhead locator %q", locator)
+	}
+
+	divisions := []float64{18, 10, 24, 10}
+	bases := []byte{'A', '0', 'A', '0'}
+	lat, lon := 0.0, 0.0
+	lonSize, latSize := 360.0, 180.0
+	for i := 0; i < len(upper)/2; i++ {
+		lonSize /= divisions[i]
+		latSize /= divisions[i]
+		lon += float64(upper[2*i]-bases[i]) * lonSize
+		lat += float64(upper[2*i+1]-bases[i]) * latSize
+	}
+	return lat + latSize/2 - 90, lon + lonSize/2 - 180, nil
+}
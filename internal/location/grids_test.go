@@ -0,0 +1,102 @@
+package location
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseGrids(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expectLat float64
+		expectLon float64
+		expectErr bool
+	}{
+		{name: "geohash", input: "geo:ezs42", expectLat: 42.605, expectLon: -5.603},
+		{name: "geohash uppercase prefix", input: "GEO:9q8yy", expectLat: 37.771, expectLon: -122.410},
+		{name: "geo URI", input: "geo:37.78,-122.41", expectLat: 37.78, expectLon: -122.41},
+		{name: "invalid geohash", input: "geo:9q8ya", expectErr: true},
+		{name: "plus code", input: "849VCWC8+R9", expectLat: 37.4220, expectLon: -122.0841},
+		{name: "lowercase plus code", input: "849vcwc8+r9", expectLat: 37.4220, expectLon: -122.0841},
+		{name: "padded plus code", input: "849V0000+", expectLat: 37.5, expectLon: -122.5},
+		{name: "short plus code", input: "CWC8+R9", expectErr: true},
+		{name: "plus code out of range", input: "X49VCWC8+R9", expectErr: true},
+		{name: "maidenhead subsquare", input: "CM87wj", expectLat: 37.396, expectLon: -122.125},
+		{name: "maidenhead square", input: "FN31", expectLat: 41.5, expectLon: -73},
+		{name: "maidenhead extended", input: "FN31pr21", expectLat: 41.715, expectLon: -72.729},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := Parse(tt.input)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Parse() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+			if loc.Type != TypeCoords {
+				t.Errorf("Expected type TypeCoords, got %v", loc.Type)
+			}
+			if math.Abs(loc.Latitude-tt.expectLat) > 0.005 || math.Abs(loc.Longitude-tt.expectLon) > 0.005 {
+				t.Errorf("Expected %f,%f, got %f,%f", tt.expectLat, tt.expectLon, loc.Latitude, loc.Longitude)
+			}
+		})
+	}
+}
+
+func TestParseGridsLeaveCitiesAlone(t *testing.T) {
+	for _, input := range []string{"NY", "Reno", "Oslo", "Boise,ID"} {
+		loc, err := Parse(input)
+		if err != nil || loc.Type != TypeCity {
+			t.Errorf("Expected %q to parse as a city, got %+v, %v", input, loc, err)
+		}
+	}
+}
+
+func TestEncodeGrids(t *testing.T) {
+	tests := []struct {
+		name   string
+		encode func(lat, lon float64) string
+		lat    float64
+		lon    float64
+		want   string
+	}{
+		{"geohash", func(lat, lon float64) string { return EncodeGeohash(lat, lon, 5) }, 42.605, -5.603, "ezs42"},
+		{"geohash precise", func(lat, lon float64) string { return EncodeGeohash(lat, lon, 9) }, 37.7749, -122.4194, "9q8yyk8yt"},
+		{"plus code", func(lat, lon float64) string { return EncodePlusCode(lat, lon, 10) }, 37.42205, -122.08406, "849VCWC8+R9"},
+		{"plus code padded", func(lat, lon float64) string { return EncodePlusCode(lat, lon, 4) }, 37.42205, -122.08406, "849V0000+"},
+		{"plus code grid", func(lat, lon float64) string { return EncodePlusCode(lat, lon, 11) }, 37.42205, -122.08406, "849VCWC8+R9G"},
+		{"maidenhead", func(lat, lon float64) string { return EncodeMaidenhead(lat, lon, 6) }, 37.4, -122.12, "CM87wj"},
+		{"maidenhead extended", func(lat, lon float64) string { return EncodeMaidenhead(lat, lon, 8) }, 41.7147, -72.7272, "FN31pr21"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.encode(tt.lat, tt.lon); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGridsRoundTrip(t *testing.T) {
+	points := [][2]float64{{0, 0}, {-33.8688, 151.2093}, {64.1466, -21.9426}, {-89.9, 179.9}, {90, -180}}
+	for _, p := range points {
+		hash := EncodeGeohash(p[0], p[1], 9)
+		if lat, lon, err := DecodeGeohash(hash); err != nil || math.Abs(lat-p[0]) > 0.0001 || math.Abs(lon-p[1]) > 0.0001 {
+			t.Errorf("Geohash %s of %v decoded to %f,%f, %v", hash, p, lat, lon, err)
+		}
+
+		code := EncodePlusCode(p[0], p[1], 10)
+		if lat, lon, err := DecodePlusCode(code); err != nil || math.Abs(lat-p[0]) > 0.0002 || math.Abs(lon-p[1]) > 0.0002 {
+			t.Errorf("Plus code %s of %v decoded to %f,%f, %v", code, p, lat, lon, err)
+		}
+
+		locator := EncodeMaidenhead(p[0], p[1], 8)
+		if lat, lon, err := DecodeMaidenhead(locator); err != nil || math.Abs(lat-p[0]) > 0.005 || math.Abs(lon-p[1]) > 0.01 {
+			t.Errorf("Locator %s of %v decoded to %f,%f, %v", locator, p, lat, lon, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package location
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+
+	"github.com/james-see/weatherornot/internal/gazetteer"
+)
+
+//go:embed countries.tsv
+var countriesTSV string
+
+//go:embed subdivisions.tsv
+var subdivisionsTSV string
+
+// Country is an ISO 3166-1 country
+type Country struct {
+	Alpha2 string
+	Alpha3 string
+	Name   string
+}
+
+// Subdivision is an ISO 3166-2 state, province or region
+type Subdivision struct {
+	Country string // ISO 3166-1 alpha-2 code
+	Code    string // code within the country, e.g. "TX" for US-TX
+	Name    string
+}
+
+var (
+	isoOnce      sync.Once
+	countries    map[string]Country       // keyed by codes and normalized names
+	subdivisions map[string][]Subdivision // keyed by codes and normalized names
+)
+
+// loadISO parses the embedded country and subdivision tables once
+func loadISO() {
+	isoOnce.Do(func() {
+		countries = make(map[string]Country)
+		for _, f := range tableRows(countriesTSV, 4) {
+			c := Country{Alpha2: f[0], Alpha3: f[1], Name: f[2]}
+			countries[c.Alpha2] = c
+			countries[c.Alpha3] = c
+			for _, name := range append([]string{c.Name}, splitAliases(f[3])...) {
+				countries[gazetteer.Normalize(name)] = c
+			}
+		}
+
+		subdivisions = make(map[string][]Subdivision)
+		for _, f := range tableRows(subdivisionsTSV, 3) {
+			country, code, ok := strings.Cut(f[0], "-")
+			if !ok {
+				continue
+			}
+			s := Subdivision{Country: country, Code: code, Name: f[1]}
+			keys := []string{code}
+			for _, name := range append([]string{s.Name}, splitAliases(f[2])...) {
+				keys = append(keys, gazetteer.Normalize(name))
+			}
+			for _, key := range keys {
+				subdivisions[key] = append(subdivisions[key], s)
+			}
+		}
+	})
+}
+
+// tableRows returns the tab-separated fields of each row of an embedded
+// table, skipping comments and rows with the wrong number of fields
+func tableRows(table string, fields int) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(table, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if f := strings.Split(line, "\t"); len(f) == fields {
+			rows = append(rows, f)
+		}
+	}
+	return rows
+}
+
+// splitAliases splits a comma-separated alias column
+func splitAliases(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// LookupCountry finds a country by alpha-2 or alpha-3 code, name or a
+// common alias such as "UK" or "Holland", ignoring case and accents
+func LookupCountry(s string) (Country, bool) {
+	loadISO()
+	s = strings.TrimSpace(s)
+	if isCode(s) {
+		if c, ok := countries[strings.ToUpper(s)]; ok {
+			return c, true
+		}
+	}
+	c, ok := countries[gazetteer.Normalize(s)]
+	return c, ok
+}
+
+// LookupSubdivisions finds the states, provinces or regions with a code or
+// name, such as "TX", "Texas" or "Bavaria". A non-empty country limits the
+// search to that country.
+func LookupSubdivisions(s, country string) []Subdivision {
+	loadISO()
+	s = strings.TrimSpace(s)
+	var found []Subdivision
+	if isCode(s) {
+		found = subdivisions[strings.ToUpper(s)]
+	}
+	if len(found) == 0 {
+		found = subdivisions[gazetteer.Normalize(s)]
+	}
+	if country == "" {
+		return found
+	}
+
+	var inCountry []Subdivision
+	for _, sub := range found {
+		if sub.Country == country {
+			inCountry = append(inCountry, sub)
+		}
+	}
+	return inCountry
+}
+
+// isCode reports whether s looks like a two- or three-letter code rather
+// than a name
+func isCode(s string) bool {
+	if len(s) < 2 || len(s) > 3 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// geocoderState returns the form of a subdivision the geocoders match on:
+// the postal code for US states, as in "Austin,TX", and the name
+// elsewhere, as admin1 names are what the geocoding services return
+func (s Subdivision) geocoderState() string {
+	if s.Country == "US" {
+		return s.Code
+	}
+	return s.Name
+}
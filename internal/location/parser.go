@@ -1,10 +1,11 @@
 package location
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
+	"unicode"
 )
 
 // LocationType represents the type of location input
@@ -15,8 +16,25 @@ const (
 	TypeZip
 	TypeCity
 	TypeCoords
+	TypeAirport
 )
 
+// String returns the name of a location type
+func (t LocationType) String() string {
+	switch t {
+	case TypeZip:
+		return "postal code"
+	case TypeCity:
+		return "city"
+	case TypeCoords:
+		return "coordinates"
+	case TypeAirport:
+		return "airport"
+	default:
+		return "unknown"
+	}
+}
+
 // ParsedLocation represents a parsed location input
 type ParsedLocation struct {
 	Type        LocationType
@@ -27,107 +45,144 @@ type ParsedLocation struct {
 	Country     string
 	Latitude    float64
 	Longitude   float64
+	Airport     string // IATA or ICAO code as entered
+	Name        string // display name of an airport
+	Confidence  float64
 }
 
-// Parse parses a location string and determines its type
+// How sure Parse is that it read the input as meant
+const (
+	// ConfidenceCertain is for unambiguous input such as coordinates or a
+	// postal code with its country
+	ConfidenceCertain = 1.0
+	// ConfidenceInferred is for input whose country was inferred from its
+	// shape, such as a Canadian postal code
+	ConfidenceInferred = 0.9
+	// ConfidenceLikely is for the usual reading of ambiguous input, such
+	// as five digits taken as a US ZIP code
+	ConfidenceLikely = 0.7
+	// ConfidenceGuess is for free text passed on to the geocoder
+	ConfidenceGuess = 0.5
+)
+
+// airportPrefix marks input that must be read as an airport code
+const airportPrefix = "airport:"
+
+// airportCodeRegex matches a bare IATA or ICAO code such as "SFO" or "KSFO"
+var airportCodeRegex = regexp.MustCompile(`^[A-Z]{3,4}$`)
+
+// Parse parses a location string and determines its type. Input that
+// cannot be read returns a *ParseError.
 func Parse(input string) (*ParsedLocation, error) {
-	if input == "" {
-		return nil, fmt.Errorf("location input cannot be empty")
+	return Parser{}.Parse(input)
+}
+
+// Parser parses location strings. Known holds names the user has used
+// before, such as favorites and recent locations, which are suggested
+// alongside known cities when input cannot be read.
+type Parser struct {
+	Known []string
+}
+
+// Parse parses a location string and determines its type. Input that
+// cannot be read returns a *ParseError with suggestions.
+func (p Parser) Parse(input string) (*ParsedLocation, error) {
+	loc, err := parse(input)
+	if err != nil {
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			perr = &ParseError{Input: input, Reason: err.Error()}
+		}
+		if perr.Suggestions == nil {
+			perr.Suggestions = p.Suggest(input)
+		}
+		return nil, perr
 	}
+	return loc, nil
+}
 
+// parse tries each input format in turn
+func parse(input string) (*ParsedLocation, error) {
 	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("location input cannot be empty")
+	}
 
 	// Try to parse as coordinates (lat,lng)
-	if loc, ok := parseCoords(input); ok {
-		return loc, nil
+	if loc, ok, err := parseCoords(input); ok || err != nil {
+		return loc, err
 	}
 
-	// Try to parse as zip code
-	if loc, ok := parseZip(input); ok {
-		return loc, nil
+	// Try to parse as a geohash, Plus Code or Maidenhead locator
+	if loc, ok, err := parseGrid(input); ok || err != nil {
+		return loc, err
 	}
 
-	// Otherwise, treat as city/state/country
-	return parseCity(input), nil
-}
+	// Try to parse as an airport code ("SFO", "KSFO", "airport:LHR")
+	if loc, ok, err := parseAirport(input); ok || err != nil {
+		return loc, err
+	}
 
-// parseCoords tries to parse input as coordinates
-func parseCoords(input string) (*ParsedLocation, bool) {
-	// Match patterns like: "40.7128,-74.0060" or "40.7128, -74.0060"
-	coordRegex := regexp.MustCompile(`^(-?\d+\.?\d*)\s*,\s*(-?\d+\.?\d*)$`)
-	matches := coordRegex.FindStringSubmatch(input)
-	
-	if len(matches) != 3 {
-		return nil, false
+	// Try to parse as zip code
+	if loc, ok, err := parseZip(input); ok || err != nil {
+		return loc, err
 	}
 
-	lat, err1 := strconv.ParseFloat(matches[1], 64)
-	lng, err2 := strconv.ParseFloat(matches[2], 64)
+	// Otherwise, treat as city/state/country
+	return parseCity(input)
+}
 
-	if err1 != nil || err2 != nil {
-		return nil, false
+// parseAirport tries to parse input as an airport code. A bare code only
+// counts when written in capitals and found in the airport table, so that
+// short city names like "Rome" or "nyc" are left alone; with the airport:
+// prefix an unknown code is an error.
+func parseAirport(input string) (*ParsedLocation, bool, error) {
+	code := input
+	prefixed := len(input) >= len(airportPrefix) && strings.EqualFold(input[:len(airportPrefix)], airportPrefix)
+	if prefixed {
+		code = strings.ToUpper(strings.TrimSpace(input[len(airportPrefix):]))
+	} else if !airportCodeRegex.MatchString(code) {
+		return nil, false, nil
 	}
 
-	// Validate coordinate ranges
-	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
-		return nil, false
+	a, ok := LookupAirport(code)
+	if !ok {
+		if prefixed {
+			return nil, false, fmt.Errorf("unknown airport code %q", code)
+		}
+		return nil, false, nil
 	}
 
 	return &ParsedLocation{
-		Type:      TypeCoords,
-		Latitude:  lat,
-		Longitude: lng,
-	}, true
+		Type:       TypeAirport,
+		Airport:    code,
+		Name:       fmt.Sprintf("%s (%s)", a.Name, code),
+		Country:    a.Country,
+		Latitude:   a.Latitude,
+		Longitude:  a.Longitude,
+		Confidence: ConfidenceCertain,
+	}, true, nil
 }
 
-// parseZip tries to parse input as a zip code
-func parseZip(input string) (*ParsedLocation, bool) {
-	// US ZIP: 5 digits or 5+4 format
-	usZipRegex := regexp.MustCompile(`^(\d{5})(-\d{4})?$`)
-	if matches := usZipRegex.FindStringSubmatch(input); matches != nil {
-		return &ParsedLocation{
-			Type:        TypeZip,
-			Zip:         matches[1],
-			CountryCode: "US",
-		}, true
-	}
-
-	// ZIP with country code: "12345,US" or "SW1A 1AA,GB"
-	// Must contain at least one digit to be considered a zip code
-	zipWithCountryRegex := regexp.MustCompile(`^([A-Z0-9]+(?:[\s-][A-Z0-9]+)?),\s*([A-Z]{2})$`)
-	upperInput := strings.ToUpper(input)
-	if matches := zipWithCountryRegex.FindStringSubmatch(upperInput); matches != nil {
-		// Additional check: must contain at least one digit to be a zip code
-		firstPart := matches[1]
-		if !regexp.MustCompile(`\d`).MatchString(firstPart) {
-			// No digits found - likely a city name, not a zip
-			return nil, false
-		}
-		return &ParsedLocation{
-			Type:        TypeZip,
-			Zip:         strings.TrimSpace(matches[1]),
-			CountryCode: matches[2],
-		}, true
+// parseCity parses input as city/state/country. States and countries may
+// be given by code, name or alias; "Paris,France" and "Austin,Texas,USA"
+// become "Paris" in FR and "Austin", TX in US.
+func parseCity(input string) (*ParsedLocation, error) {
+	parts := strings.Split(input, ",")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("too many commas; write City, City,State or City,State,Country")
 	}
-
-	// Just digits, assume US ZIP
-	if matched, _ := regexp.MatchString(`^\d{5}$`, input); matched {
-		return &ParsedLocation{
-			Type:        TypeZip,
-			Zip:         input,
-			CountryCode: "US",
-		}, true
+	city := strings.TrimSpace(parts[0])
+	if city == "" {
+		return nil, fmt.Errorf("missing city name before the comma")
+	}
+	if !strings.ContainsFunc(city, unicode.IsLetter) {
+		return nil, fmt.Errorf("%q is neither a place name nor a postal code in a known format", city)
 	}
 
-	return nil, false
-}
-
-// parseCity parses input as city/state/country
-func parseCity(input string) *ParsedLocation {
-	parts := strings.Split(input, ",")
-	
 	loc := &ParsedLocation{
-		Type: TypeCity,
+		Type:       TypeCity,
+		Confidence: ConfidenceGuess,
 	}
 
 	switch len(parts) {
@@ -137,22 +192,51 @@ func parseCity(input string) *ParsedLocation {
 	case 2:
 		// City, State or City, Country
 		loc.City = strings.TrimSpace(parts[0])
-		second := strings.TrimSpace(parts[1])
-		
-		// Treat 2-letter codes as state (US convention: "City,ST")
-		// For international use, specify as: "City,State,CC" or just "City" for well-known cities
-		loc.State = second
+		loc.setRegion(strings.TrimSpace(parts[1]))
 	case 3:
 		// City, State, Country
 		loc.City = strings.TrimSpace(parts[0])
 		loc.State = strings.TrimSpace(parts[1])
-		loc.Country = strings.TrimSpace(strings.ToUpper(parts[2]))
-	default:
-		// More than 3 parts, just take first as city
-		loc.City = strings.TrimSpace(parts[0])
+		country := strings.TrimSpace(parts[2])
+		c, ok := LookupCountry(country)
+		if !ok {
+			return nil, &ParseError{
+				Input:       input,
+				Reason:      fmt.Sprintf("%q is not a country code or name", country),
+				Suggestions: suggestCountries(loc.City, loc.State, country),
+			}
+		}
+		loc.Country = c.Alpha2
+		if subs := LookupSubdivisions(loc.State, c.Alpha2); len(subs) == 1 {
+			loc.State = subs[0].geocoderState()
+			loc.Confidence = ConfidenceLikely
+		}
 	}
 
-	return loc
+	return loc, nil
+}
+
+// setRegion reads the part after the city in "City,X", which may name a
+// state or a country. When it could be either, such as "CA" for California
+// or Canada and "Georgia" for the state or the country, it is kept as the
+// state; the geocoders then accept a match on either.
+func (l *ParsedLocation) setRegion(region string) {
+	country, isCountry := LookupCountry(region)
+	subs := LookupSubdivisions(region, "")
+
+	switch {
+	case isCountry && len(subs) == 0:
+		l.Country = country.Alpha2
+		l.Confidence = ConfidenceLikely
+	case !isCountry && len(subs) == 1:
+		l.State = subs[0].geocoderState()
+		l.Country = subs[0].Country
+		l.Confidence = ConfidenceLikely
+	case isCode(region):
+		l.State = strings.ToUpper(region)
+	default:
+		l.State = region
+	}
 }
 
 // isAllUpperOrLower checks if a string is all uppercase or all lowercase
@@ -167,6 +251,8 @@ func (l *ParsedLocation) String() string {
 		return fmt.Sprintf("ZIP: %s, %s", l.Zip, l.CountryCode)
 	case TypeCoords:
 		return fmt.Sprintf("Coordinates: %.4f, %.4f", l.Latitude, l.Longitude)
+	case TypeAirport:
+		return fmt.Sprintf("Airport: %s, %.4f, %.4f", l.Name, l.Latitude, l.Longitude)
 	case TypeCity:
 		parts := []string{l.City}
 		if l.State != "" {
@@ -1,6 +1,9 @@
 package location
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -57,6 +60,46 @@ func TestParseCoordinates(t *testing.T) {
 	}
 }
 
+func TestParseAirport(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		expectType LocationType
+		expectName string
+		expectErr  bool
+	}{
+		{name: "IATA code", input: "SFO", expectType: TypeAirport, expectName: "San Francisco International Airport (SFO)"},
+		{name: "ICAO code", input: "KSFO", expectType: TypeAirport, expectName: "San Francisco International Airport (KSFO)"},
+		{name: "prefixed code", input: "airport:LHR", expectType: TypeAirport, expectName: "Heathrow Airport (LHR)"},
+		{name: "prefixed lowercase code", input: "Airport: egll", expectType: TypeAirport, expectName: "Heathrow Airport (EGLL)"},
+		{name: "unknown prefixed code", input: "airport:XQZ", expectErr: true},
+		{name: "lowercase code stays a city", input: "sfo", expectType: TypeCity},
+		{name: "unknown code stays a city", input: "NYC", expectType: TypeCity},
+		{name: "short city name", input: "Rome", expectType: TypeCity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := Parse(tt.input)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Parse() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+			if loc.Type != tt.expectType {
+				t.Errorf("Expected type %v, got %v", tt.expectType, loc.Type)
+			}
+			if loc.Name != tt.expectName {
+				t.Errorf("Expected name %q, got %q", tt.expectName, loc.Name)
+			}
+			if loc.Type == TypeAirport && (loc.Latitude == 0 || loc.Longitude == 0) {
+				t.Errorf("Expected airport coordinates, got %f,%f", loc.Latitude, loc.Longitude)
+			}
+		})
+	}
+}
+
 func TestParseZipCode(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -127,12 +170,110 @@ func TestParseCity(t *testing.T) {
 			expectCountry: "",
 		},
 		{
-			name:          "city and country code (treated as state)",
+			name:          "city and country code",
 			input:         "Paris,FR",
 			expectCity:    "Paris",
-			expectState:   "FR",
+			expectState:   "",
+			expectCountry: "FR",
+		},
+		{
+			name:          "city and country name",
+			input:         "Paris,France",
+			expectCity:    "Paris",
+			expectState:   "",
+			expectCountry: "FR",
+		},
+		{
+			name:          "city and country alias",
+			input:         "Manchester, UK",
+			expectCity:    "Manchester",
+			expectState:   "",
+			expectCountry: "GB",
+		},
+		{
+			name:          "city and accented country name",
+			input:         "Zürich,Schweiz",
+			expectCity:    "Zürich",
+			expectState:   "",
+			expectCountry: "CH",
+		},
+		{
+			name:          "city and alpha-3 country code",
+			input:         "Osaka,JPN",
+			expectCity:    "Osaka",
+			expectState:   "",
+			expectCountry: "JP",
+		},
+		{
+			name:          "city and state name",
+			input:         "Austin,Texas",
+			expectCity:    "Austin",
+			expectState:   "TX",
+			expectCountry: "US",
+		},
+		{
+			name:          "city and lowercase state code",
+			input:         "Austin,tx",
+			expectCity:    "Austin",
+			expectState:   "TX",
+			expectCountry: "US",
+		},
+		{
+			name:          "city and province code",
+			input:         "London,ON",
+			expectCity:    "London",
+			expectState:   "Ontario",
+			expectCountry: "CA",
+		},
+		{
+			name:          "city and region alias",
+			input:         "Munich,Bayern",
+			expectCity:    "Munich",
+			expectState:   "Bavaria",
+			expectCountry: "DE",
+		},
+		{
+			name:          "state name that is also a country",
+			input:         "Atlanta,Georgia",
+			expectCity:    "Atlanta",
+			expectState:   "Georgia",
+			expectCountry: "",
+		},
+		{
+			name:          "code that is a state and a country",
+			input:         "Springfield,il",
+			expectCity:    "Springfield",
+			expectState:   "IL",
 			expectCountry: "",
 		},
+		{
+			name:          "unknown region",
+			input:         "Springfield,Nowhere",
+			expectCity:    "Springfield",
+			expectState:   "Nowhere",
+			expectCountry: "",
+		},
+		{
+			name:          "city, state name and country alias",
+			input:         "Austin,Texas,USA",
+			expectCity:    "Austin",
+			expectState:   "TX",
+			expectCountry: "US",
+		},
+		{
+			name:          "city, state code and country name",
+			input:         "Sydney,NSW,Australia",
+			expectCity:    "Sydney",
+			expectState:   "New South Wales",
+			expectCountry: "AU",
+		},
+		{
+			name:          "city, region and country",
+			input:         "Edinburgh,Scotland,United Kingdom",
+			expectCity:    "Edinburgh",
+			expectState:   "Scotland",
+			expectCountry: "GB",
+		},
 		{
 			name:          "city, state, and country",
 			input:         "New York,NY,US",
@@ -179,6 +320,98 @@ func TestParseEmptyString(t *testing.T) {
 	}
 }
 
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name              string
+		input             string
+		known             []string
+		expectReason      string
+		expectSuggestions []string
+	}{
+		{
+			name:         "blank",
+			input:        "   ",
+			expectReason: "location input cannot be empty",
+		},
+		{
+			name:              "too many parts",
+			input:             "Springfield,IL,US,Earth",
+			expectReason:      "too many commas",
+			expectSuggestions: []string{"Springfield,IL,US"},
+		},
+		{
+			name:         "missing city",
+			input:        ",CA",
+			expectReason: "missing city name",
+		},
+		{
+			name:         "no letters",
+			input:        "123",
+			expectReason: `"123" is neither a place name nor a postal code`,
+		},
+		{
+			name:              "misspelled country",
+			input:             "Lyon,,Frnace",
+			expectReason:      `"Frnace" is not a country code or name`,
+			expectSuggestions: []string{"Lyon,,FR"},
+		},
+		{
+			name:              "unknown country suggests cities",
+			input:             "Sprngfield,IL,XX",
+			expectReason:      `"XX" is not a country code or name`,
+			expectSuggestions: []string{"Springfield,IL,US"},
+		},
+		{
+			name:              "out of range coordinates suggest known names",
+			input:             "100,200",
+			known:             []string{"100,20", "home"},
+			expectReason:      "latitude 100 is out of range",
+			expectSuggestions: []string{"100,20"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parser{Known: tt.known}.Parse(tt.input)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Expected a *ParseError, got %v", err)
+			}
+			if perr.Input != tt.input {
+				t.Errorf("Expected input %q, got %q", tt.input, perr.Input)
+			}
+			if !strings.Contains(perr.Reason, tt.expectReason) {
+				t.Errorf("Expected reason containing %q, got %q", tt.expectReason, perr.Reason)
+			}
+			if !reflect.DeepEqual(perr.Suggestions, tt.expectSuggestions) {
+				t.Errorf("Expected suggestions %q, got %q", tt.expectSuggestions, perr.Suggestions)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	p := Parser{Known: []string{"home", "Portland,OR", "40.7128,-74.0060"}}
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"homw", []string{"home"}},
+		{"Portlnd,OR", []string{"Portland,OR", "Portland,OR,US"}},
+		{"San Fransisco", []string{"San Francisco,CA,US"}},
+		{"Philadelpia", []string{"Philadelphia,PA,US"}},
+		{"Xyzzy", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := p.Suggest(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestLocationString(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -213,6 +446,17 @@ func TestLocationString(t *testing.T) {
 			},
 			expected: "City: San Francisco, CA, US",
 		},
+		{
+			name: "airport",
+			loc: &ParsedLocation{
+				Type:      TypeAirport,
+				Airport:   "SFO",
+				Name:      "San Francisco International Airport (SFO)",
+				Latitude:  37.6190,
+				Longitude: -122.3749,
+			},
+			expected: "Airport: San Francisco International Airport (SFO), 37.6190, -122.3749",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,187 @@
+package location
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// postalFormat describes the postal codes of one country
+type postalFormat struct {
+	country string
+	// pattern matches a code in capitals; its groups are joined with sep
+	// to give the normalized code
+	pattern *regexp.Regexp
+	sep     string
+	// infer matches codes whose shape identifies the country on its own;
+	// nil when the country's codes look like other countries' codes
+	infer   *regexp.Regexp
+	example string
+}
+
+// postalFormats lists the postal code formats weatherornot validates
+var postalFormats = []postalFormat{
+	{"US", regexp.MustCompile(`^(\d{5})(?:-?\d{4})?$`), "", regexp.MustCompile(`^\d{5}-\d{4}$`), "12345 or 12345-6789"},
+	{"CA", regexp.MustCompile(`^([ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z]) ?(\d[ABCEGHJ-NPRSTV-Z]\d)$`), " ",
+		regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`), "K1A 0B1"},
+	{"GB", regexp.MustCompile(`^([A-Z]{1,2}\d[A-Z\d]?) ?(\d[A-Z]{2})$`), " ",
+		regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`), "SW1A 1AA"},
+	{"IE", regexp.MustCompile(`^([AC-FHKNPRTV-Y]\d{2}|D6W) ?([\dAC-FHKNPRTV-Y]{4})$`), " ",
+		regexp.MustCompile(`^([AC-FHKNPRTV-Y]\d{2}|D6W) ?[\dAC-FHKNPRTV-Y]{4}$`), "D02 X285"},
+	{"NL", regexp.MustCompile(`^([1-9]\d{3}) ?([A-RT-Z][A-Z]|S[BCE-RT-Z])$`), " ",
+		regexp.MustCompile(`^[1-9]\d{3} ?([A-RT-Z][A-Z]|S[BCE-RT-Z])$`), "1012 AB"},
+	{"JP", regexp.MustCompile(`^(\d{3})-?(\d{4})$`), "-", regexp.MustCompile(`^\d{3}-\d{4}$`), "100-0001"},
+	{"BR", regexp.MustCompile(`^(\d{5})-?(\d{3})$`), "-", regexp.MustCompile(`^\d{5}-\d{3}$`), "01310-100"},
+	{"PT", regexp.MustCompile(`^(\d{4})-?(\d{3})$`), "-", regexp.MustCompile(`^\d{4}-\d{3}$`), "1000-001"},
+	{"PL", regexp.MustCompile(`^(\d{2})-?(\d{3})$`), "-", regexp.MustCompile(`^\d{2}-\d{3}$`), "00-950"},
+	{"DE", regexp.MustCompile(`^(\d{5})$`), "", nil, "10115"},
+	{"FR", regexp.MustCompile(`^(\d{5})$`), "", nil, "75001"},
+	{"ES", regexp.MustCompile(`^(\d{5})$`), "", nil, "28013"},
+	{"IT", regexp.MustCompile(`^(\d{5})$`), "", nil, "00184"},
+	{"MX", regexp.MustCompile(`^(\d{5})$`), "", nil, "06000"},
+	{"KR", regexp.MustCompile(`^(\d{5})$`), "", nil, "03051"},
+	{"FI", regexp.MustCompile(`^(\d{5})$`), "", nil, "00100"},
+	{"SE", regexp.MustCompile(`^(\d{3}) ?(\d{2})$`), " ", nil, "114 55"},
+	{"CZ", regexp.MustCompile(`^(\d{3}) ?(\d{2})$`), " ", nil, "110 00"},
+	{"AU", regexp.MustCompile(`^(\d{4})$`), "", nil, "2000"},
+	{"NZ", regexp.MustCompile(`^(\d{4})$`), "", nil, "6011"},
+	{"AT", regexp.MustCompile(`^(\d{4})$`), "", nil, "1010"},
+	{"BE", regexp.MustCompile(`^(\d{4})$`), "", nil, "1000"},
+	{"CH", regexp.MustCompile(`^(\d{4})$`), "", nil, "8001"},
+	{"DK", regexp.MustCompile(`^(\d{4})$`), "", nil, "1050"},
+	{"NO", regexp.MustCompile(`^(\d{4})$`), "", nil, "0150"},
+	{"ZA", regexp.MustCompile(`^(\d{4})$`), "", nil, "8001"},
+	{"IN", regexp.MustCompile(`^([1-9]\d{2}) ?(\d{3})$`), "", nil, "110001"},
+	{"CN", regexp.MustCompile(`^(\d{6})$`), "", nil, "100000"},
+	{"RU", regexp.MustCompile(`^(\d{6})$`), "", nil, "101000"},
+	{"SG", regexp.MustCompile(`^(\d{6})$`), "", nil, "018956"},
+}
+
+// postalCodeWithCountryRegex matches "CODE,CC", e.g. "K1A 0B1,CA"; the
+// country may also be an alpha-3 code or an alias such as "UK"
+var postalCodeWithCountryRegex = regexp.MustCompile(`^([A-Z0-9]+(?:[\s-][A-Z0-9]+)?)\s*,\s*([A-Z]{2,3})$`)
+
+// parseZip tries to parse input as a postal code, with or without a
+// country code. Codes are checked against the country's format and
+// normalized, e.g. "k1a0b1" becomes "K1A 0B1". Without a country code the
+// country is inferred when only one country writes codes that way; bare
+// five-digit codes are taken as US ZIP codes.
+func parseZip(input string) (*ParsedLocation, bool, error) {
+	upper := strings.Join(strings.Fields(strings.ToUpper(input)), " ")
+	// Postal codes always have a digit; city names do not
+	if !strings.ContainsAny(upper, "0123456789") {
+		return nil, false, nil
+	}
+
+	if m := postalCodeWithCountryRegex.FindStringSubmatch(upper); m != nil {
+		code, country := m[1], m[2]
+		// Letters that fit no country's postal codes are part of a place
+		// name with a number in it
+		placeName := strings.ContainsFunc(code, unicode.IsLetter) && len(postalCountries(code)) == 0
+		c, ok := LookupCountry(country)
+		if !ok {
+			if placeName {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("%q is not a country code or name", m[2])
+		}
+		country = c.Alpha2
+		f, known := postalFormatFor(country)
+		if !known && placeName {
+			return nil, false, nil
+		}
+		if !known {
+			// A country we have no format for; pass the code on as typed
+			return &ParsedLocation{
+				Type:        TypeZip,
+				Zip:         code,
+				CountryCode: country,
+				Confidence:  ConfidenceLikely,
+			}, true, nil
+		}
+		zip, ok := f.normalize(code)
+		if !ok {
+			if placeName {
+				// Such as "Route 66,US"
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("%q is not a valid %s postal code, e.g. %s", code, country, f.example)
+		}
+		return &ParsedLocation{
+			Type:        TypeZip,
+			Zip:         zip,
+			CountryCode: country,
+			Confidence:  ConfidenceCertain,
+		}, true, nil
+	}
+
+	var inferred []postalFormat
+	for _, f := range postalFormats {
+		if f.infer != nil && f.infer.MatchString(upper) {
+			inferred = append(inferred, f)
+		}
+	}
+	if len(inferred) == 1 {
+		zip, _ := inferred[0].normalize(upper)
+		return &ParsedLocation{
+			Type:        TypeZip,
+			Zip:         zip,
+			CountryCode: inferred[0].country,
+			Confidence:  ConfidenceInferred,
+		}, true, nil
+	}
+
+	// Five digits: a US ZIP code unless a country says otherwise
+	if us, _ := postalFormatFor("US"); us.pattern.MatchString(upper) {
+		zip, _ := us.normalize(upper)
+		return &ParsedLocation{
+			Type:        TypeZip,
+			Zip:         zip,
+			CountryCode: "US",
+			Confidence:  ConfidenceLikely,
+		}, true, nil
+	}
+
+	// Digits that fit several countries' codes, such as "2000"
+	if !strings.ContainsFunc(upper, unicode.IsLetter) {
+		if countries := postalCountries(upper); len(countries) > 0 {
+			return nil, false, fmt.Errorf("%q could be a postal code in %s; add the country, e.g. \"%s,%s\"",
+				input, joinOr(countries), upper, countries[0])
+		}
+	}
+
+	return nil, false, nil
+}
+
+// normalize checks code against the format and returns it written the
+// standard way
+func (f postalFormat) normalize(code string) (string, bool) {
+	m := f.pattern.FindStringSubmatch(code)
+	if m == nil {
+		return "", false
+	}
+	return strings.Join(m[1:], f.sep), true
+}
+
+// postalFormatFor returns the postal code format of a country
+func postalFormatFor(country string) (postalFormat, bool) {
+	for _, f := range postalFormats {
+		if f.country == country {
+			return f, true
+		}
+	}
+	return postalFormat{}, false
+}
+
+// postalCountries returns the countries whose postal code format a code
+// fits, in table order
+func postalCountries(code string) []string {
+	var countries []string
+	for _, f := range postalFormats {
+		if f.pattern.MatchString(code) {
+			countries = append(countries, f.country)
+		}
+	}
+	return countries
+}
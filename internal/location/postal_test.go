@@ -0,0 +1,128 @@
+package location
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePostalCodes(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectZip        string
+		expectCountry    string
+		expectConfidence float64
+	}{
+		{"US ZIP", "90210", "90210", "US", ConfidenceLikely},
+		{"US ZIP+4", "90210-1234", "90210", "US", ConfidenceInferred},
+		{"US ZIP with country", "10001,US", "10001", "US", ConfidenceCertain},
+		{"Canada", "K1A 0B1", "K1A 0B1", "CA", ConfidenceInferred},
+		{"Canada lowercase without space", "k1a0b1", "K1A 0B1", "CA", ConfidenceInferred},
+		{"UK postcode", "SW1A 1AA", "SW1A 1AA", "GB", ConfidenceInferred},
+		{"UK postcode short outward code", "M1 1AE", "M1 1AE", "GB", ConfidenceInferred},
+		{"UK postcode without space", "sw1a1aa,gb", "SW1A 1AA", "GB", ConfidenceCertain},
+		{"UK alias", "EC1A 1BB, UK", "EC1A 1BB", "GB", ConfidenceCertain},
+		{"alpha-3 country code", "K1A 0B1,CAN", "K1A 0B1", "CA", ConfidenceCertain},
+		{"Ireland", "D02 X285", "D02 X285", "IE", ConfidenceInferred},
+		{"Netherlands", "1012 AB", "1012 AB", "NL", ConfidenceInferred},
+		{"Netherlands without space", "1012ab", "1012 AB", "NL", ConfidenceInferred},
+		{"Japan", "100-0001", "100-0001", "JP", ConfidenceInferred},
+		{"Japan digits only with country", "1000001,JP", "100-0001", "JP", ConfidenceCertain},
+		{"Brazil", "01310-100", "01310-100", "BR", ConfidenceInferred},
+		{"Portugal", "1000-001", "1000-001", "PT", ConfidenceInferred},
+		{"Poland", "00-950", "00-950", "PL", ConfidenceInferred},
+		{"Germany", "10115,DE", "10115", "DE", ConfidenceCertain},
+		{"Sweden", "11455,SE", "114 55", "SE", ConfidenceCertain},
+		{"Australia", "2000,AU", "2000", "AU", ConfidenceCertain},
+		{"country without a known format", "1100,AR", "1100", "AR", ConfidenceLikely},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if loc.Type != TypeZip {
+				t.Fatalf("Expected type TypeZip, got %v", loc.Type)
+			}
+			if loc.Zip != tt.expectZip {
+				t.Errorf("Expected zip %s, got %s", tt.expectZip, loc.Zip)
+			}
+			if loc.CountryCode != tt.expectCountry {
+				t.Errorf("Expected country %s, got %s", tt.expectCountry, loc.CountryCode)
+			}
+			if loc.Confidence != tt.expectConfidence {
+				t.Errorf("Expected confidence %.1f, got %.1f", tt.expectConfidence, loc.Confidence)
+			}
+		})
+	}
+}
+
+func TestParsePostalCodeErrors(t *testing.T) {
+	tests := []struct {
+		input     string
+		expectErr string
+	}{
+		{"K1A 0B1,US", `"K1A 0B1" is not a valid US postal code`},
+		{"90210,CA", `"90210" is not a valid CA postal code`},
+		{"1012 AB,GB", `"1012 AB" is not a valid GB postal code`},
+		{"123,DE", `"123" is not a valid DE postal code`},
+		{"12345,XYZ", `"XYZ" is not a country code or name`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestParsePostalCodeLookalikes(t *testing.T) {
+	// Place names with a number that fit no postal code format
+	tests := []struct {
+		input         string
+		expectCity    string
+		expectCountry string
+	}{
+		{"Route 66,US", "Route 66", "US"},
+		{"Area 51,USA", "Area 51", "US"},
+		{"Ruta 40,ARG", "Ruta 40", "AR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			loc, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if loc.Type != TypeCity || loc.City != tt.expectCity || loc.Country != tt.expectCountry {
+				t.Errorf("Expected city %q in %s, got %+v", tt.expectCity, tt.expectCountry, loc)
+			}
+		})
+	}
+}
+
+func TestParsePostalCodeAmbiguous(t *testing.T) {
+	// Four digits fit many countries and seven fit both Japan and
+	// Portugal; without a country they are not guessed at
+	tests := []struct {
+		input     string
+		expectErr string
+	}{
+		{"2000", `"2000" could be a postal code in AU, NZ, AT, BE, CH, DK, NO or ZA; add the country, e.g. "2000,AU"`},
+		{"1000001", `"1000001" could be a postal code in JP or PT`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}